@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/bench"
+	"github.com/joseEnrique/pvcusage/internal/display"
+)
+
+// newBenchCmd builds the bench command: run fio profiles against a scratch PVC
+func newBenchCmd(o *cliOptions) *cobra.Command {
+	var opts bench.Options
+	var profiles, image string
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark a StorageClass by running fio against a scratch PVC",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selected, err := bench.SelectProfiles(profiles)
+			if err != nil {
+				return err
+			}
+			opts.Profiles = selected
+			opts.Pod = bench.DefaultPodSettings()
+			opts.Pod.Image = image
+			// Checked before anything is created, as fio would only fail inside the pod
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			if opts.Namespace, err = o.namespace(); err != nil {
+				return err
+			}
+
+			// Cancelling on a signal lets Run delete the scratch PVC and pod
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			report, err := bench.Run(ctx, client, opts)
+			if err != nil {
+				return fmt.Errorf("error running benchmark: %v", err)
+			}
+
+			display.ShowBenchReport(report)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.StorageClass, "storage-class", "", "StorageClass of the scratch PVC (default: the cluster's default class)")
+	cmd.Flags().StringVar(&opts.Size, "size", "10Gi", "Size of the scratch PVC (at least "+bench.MinSize+")")
+	cmd.Flags().StringVar(&image, "image", bench.DefaultImage, "Container image providing fio")
+	cmd.Flags().DurationVar(&opts.Runtime, "runtime", 30*time.Second, "Runtime of each fio profile")
+	cmd.Flags().StringVar(&profiles, "profiles", "", "Comma-separated fio profiles to run (randread-4k, randwrite-4k, seqread-1m, seqwrite-1m, mixed-70-30)")
+	return cmd
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// newCapacityCmd builds the capacity command: requested, status, PV and
+// filesystem sizes side by side
+func newCapacityCmd(o *cliOptions) *cobra.Command {
+	var opts pvc.Options
+	var output string
+	var tolerance float64
+	var issuesOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "capacity",
+		Short: "Compare the requested, status, PV and filesystem sizes of PVCs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "json", "yaml"); err != nil {
+				return err
+			}
+			if tolerance < 0 {
+				return fmt.Errorf("--tolerance must not be negative")
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.listNamespace()
+			if err != nil {
+				return err
+			}
+			opts.Namespace = namespace
+
+			sizes, err := client.GetClaimSizes(namespace)
+			if err != nil {
+				return err
+			}
+			usages, err := pvc.GetUsages(client, opts)
+			if err != nil {
+				return err
+			}
+
+			capacities := pvc.ReconcileCapacities(sizes, usages, tolerance)
+			if issuesOnly {
+				var flagged []pvc.Capacity
+				for _, c := range capacities {
+					if c.HasIssues() {
+						flagged = append(flagged, c)
+					}
+				}
+				capacities = flagged
+			}
+
+			if output != "table" {
+				return display.PrintStructured(output, display.EmptyIfNil(capacities))
+			}
+			display.ShowCapacities(capacities)
+			return nil
+		},
+	}
+	registerOutputFlag(cmd, &output, "table", "json", "yaml")
+	cmd.Flags().Float64Var(&tolerance, "tolerance", 5, "Percentage by which a size may differ from the request before it is flagged")
+	cmd.Flags().BoolVar(&issuesOnly, "issues", false, "Only show PVCs with a pending resize or disagreeing sizes")
+	cmd.Flags().StringVar(&opts.NodeSelector, "node-selector", "", "Only collect filesystem sizes from nodes matching this label selector")
+	return cmd
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newCleanupCmd builds the cleanup command: delete leaked performance monitor pods
+func newCleanupCmd(o *cliOptions) *cobra.Command {
+	var olderThan time.Duration
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Delete performance monitor pods left behind by killed sessions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.listNamespace()
+			if err != nil {
+				return err
+			}
+
+			pods, err := client.CleanupMonitorPods(namespace, olderThan, dryRun)
+			for _, pod := range pods {
+				if dryRun {
+					fmt.Printf("Would delete %s\n", pod)
+				} else {
+					fmt.Printf("Deleted %s\n", pod)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("error cleaning up monitor pods: %v", err)
+			}
+			if len(pods) == 0 {
+				fmt.Println("No leaked monitor pods found")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only delete monitor pods without a heartbeat for this long (e.g. 30m)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the monitor pods that would be deleted without deleting them")
+	return cmd
+}
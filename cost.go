@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/cost"
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// costReport is the JSON and YAML form of the cost tables
+type costReport struct {
+	Currency   string               `json:"currency,omitempty"`
+	PVCs       []cost.Cost          `json:"pvcs,omitempty"`
+	Namespaces []cost.NamespaceCost `json:"namespaces"`
+}
+
+// newCostCmd builds the cost command: monthly storage cost per PVC and namespace
+func newCostCmd(o *cliOptions) *cobra.Command {
+	var opts pvc.Options
+	var output string
+	var rollup bool
+
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Estimate the monthly storage cost of PVCs and namespaces",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "json", "yaml", "csv"); err != nil {
+				return err
+			}
+			if o.config == nil || o.config.Pricing.IsZero() {
+				return fmt.Errorf("no pricing configured, add a pricing section to %s", o.configPath)
+			}
+			pricing := o.config.Pricing
+
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.listNamespace()
+			if err != nil {
+				return err
+			}
+			opts.Namespace = namespace
+
+			sizes, err := client.GetClaimSizes(namespace)
+			if err != nil {
+				return err
+			}
+			usages, err := pvc.GetUsages(client, opts)
+			if err != nil {
+				return err
+			}
+
+			costs := cost.Estimate(sizes, usages, pricing)
+			if classes := cost.UnpricedClasses(costs); len(classes) > 0 {
+				log.Printf("Warning: no price for StorageClasses %s, add them to the pricing section or set a default", strings.Join(classes, ", "))
+			}
+			namespaces := cost.RollupNamespaces(costs)
+
+			switch output {
+			case "csv":
+				if rollup {
+					return display.PrintNamespaceCostsCSV(namespaces)
+				}
+				return display.PrintCostsCSV(costs)
+			case "json", "yaml":
+				report := costReport{Currency: pricing.Currency, Namespaces: display.EmptyIfNil(namespaces)}
+				if !rollup {
+					report.PVCs = costs
+				}
+				return display.PrintStructured(output, report)
+			}
+
+			if !rollup {
+				display.ShowCosts(costs, pricing.Currency)
+				fmt.Println()
+			}
+			display.ShowNamespaceCosts(namespaces, pricing.Currency)
+			return nil
+		},
+	}
+	registerOutputFlag(cmd, &output, "table", "json", "yaml", "csv")
+	cmd.Flags().BoolVar(&rollup, "rollup", false, "Only show the cost of each namespace")
+	cmd.Flags().StringVar(&opts.NodeSelector, "node-selector", "", "Only collect used space from nodes matching this label selector")
+	return cmd
+}
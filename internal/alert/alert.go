@@ -0,0 +1,113 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// sendTimeout bounds each webhook request, so that a slow sink doesn't hold up the watch
+const sendTimeout = 10 * time.Second
+
+// Sink is a webhook that receives watch events as JSON, one POST per event
+type Sink struct {
+	Webhook string            `yaml:"webhook"`
+	Headers map[string]string `yaml:"headers"` // e.g. an Authorization header
+	// Events are the event types sent to the sink. Only threshold_changed
+	// events are sent by default.
+	Events []string `yaml:"events"`
+}
+
+// Validate checks the webhook URL and the event types
+func (s Sink) Validate() error {
+	u, err := url.Parse(s.Webhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook %q is not an http or https URL", s.Webhook)
+	}
+	types := []string{pvc.EventAdded, pvc.EventRemoved, pvc.EventUsageChanged, pvc.EventThresholdChanged}
+	for _, event := range s.Events {
+		if !contains(types, event) {
+			return fmt.Errorf("event %q is not one of %s", event, strings.Join(types, ", "))
+		}
+	}
+	return nil
+}
+
+// Wants reports whether the sink receives events of the given type
+func (s Sink) Wants(eventType string) bool {
+	if len(s.Events) == 0 {
+		return eventType == pvc.EventThresholdChanged
+	}
+	return contains(s.Events, eventType)
+}
+
+// Notifier sends events to the sinks that want them
+type Notifier struct {
+	sinks  []Sink
+	client *http.Client
+}
+
+// NewNotifier creates a notifier for the sinks
+func NewNotifier(sinks []Sink) *Notifier {
+	return &Notifier{sinks: sinks, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Send posts each event to every sink that wants it. Delivery continues after
+// a failure; the errors of every failed delivery are returned.
+func (n *Notifier) Send(events []pvc.Event) []error {
+	var errs []error
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error encoding event: %v", err))
+			continue
+		}
+		for _, sink := range n.sinks {
+			if !sink.Wants(event.Type) {
+				continue
+			}
+			if err := n.post(sink, body); err != nil {
+				errs = append(errs, fmt.Errorf("error sending %s event of %s/%s to %s: %v", event.Type, event.Namespace, event.PVC, sink.Webhook, err))
+			}
+		}
+	}
+	return errs
+}
+
+// post sends one JSON body to a sink, failing on non-2xx responses
+func (n *Notifier) post(sink Sink, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, sink.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range sink.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// contains reports whether value is one of values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func TestSinkValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sink    Sink
+		wantErr bool
+	}{
+		{"https", Sink{Webhook: "https://hooks.example.com/pvc"}, false},
+		{"events", Sink{Webhook: "http://alerts:8080", Events: []string{pvc.EventAdded, pvc.EventThresholdChanged}}, false},
+		{"missing webhook", Sink{}, true},
+		{"not http", Sink{Webhook: "ftp://example.com"}, true},
+		{"no host", Sink{Webhook: "https://"}, true},
+		{"unknown event", Sink{Webhook: "https://example.com", Events: []string{"full"}}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.sink.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSinkWants(t *testing.T) {
+	defaults := Sink{}
+	if !defaults.Wants(pvc.EventThresholdChanged) || defaults.Wants(pvc.EventUsageChanged) {
+		t.Errorf("default sink should only want threshold_changed")
+	}
+	all := Sink{Events: []string{pvc.EventUsageChanged}}
+	if !all.Wants(pvc.EventUsageChanged) || all.Wants(pvc.EventThresholdChanged) {
+		t.Errorf("sink with events should only want the listed ones")
+	}
+}
+
+func TestNotifierSend(t *testing.T) {
+	var mu sync.Mutex
+	var received []pvc.Event
+	var auth string
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pvc.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		mu.Lock()
+		received = append(received, event)
+		auth = r.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	notifier := NewNotifier([]Sink{
+		{Webhook: ok.URL, Headers: map[string]string{"Authorization": "Bearer token"}},
+		{Webhook: failing.URL, Events: []string{pvc.EventAdded}},
+	})
+	errs := notifier.Send([]pvc.Event{
+		{Type: pvc.EventAdded, Namespace: "kafka", PVC: "data-0"},
+		{Type: pvc.EventThresholdChanged, Namespace: "kafka", PVC: "data-1"},
+		{Type: pvc.EventUsageChanged, Namespace: "kafka", PVC: "data-2"},
+	})
+
+	if len(received) != 1 || received[0].PVC != "data-1" || received[0].Type != pvc.EventThresholdChanged {
+		t.Errorf("received = %+v, want the threshold_changed event of data-1", received)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Authorization header = %q, want %q", auth, "Bearer token")
+	}
+	if len(errs) != 1 {
+		t.Errorf("Send() errors = %v, want one for the failing sink", errs)
+	}
+}
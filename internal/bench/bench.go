@@ -0,0 +1,237 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+// DefaultImage is a container image that ships fio
+const DefaultImage = "nixery.dev/shell/fio"
+
+// MinSize is the smallest scratch PVC fio can run the default profiles on,
+// with room for the filesystem overhead
+const MinSize = "64Mi"
+
+// Options configures a benchmark run
+type Options struct {
+	Namespace    string
+	StorageClass string        // empty uses the cluster's default StorageClass
+	Size         string        // size of the scratch PVC, e.g. "10Gi"
+	Runtime      time.Duration // runtime of each profile
+	Profiles     []Profile
+	Pod          k8s.PodSettings // settings of the pod running fio
+}
+
+// DefaultPodSettings returns the restricted settings of monitor pods with the
+// fio image, and resources that leave fio room to saturate the volume
+func DefaultPodSettings() k8s.PodSettings {
+	settings := k8s.DefaultPodSettings()
+	settings.Image = DefaultImage
+	settings.CPURequest = "500m"
+	settings.CPULimit = "2"
+	settings.MemoryRequest = "256Mi"
+	settings.MemoryLimit = "512Mi"
+	return settings
+}
+
+// Validate checks the size of the scratch PVC and the pod settings
+func (o Options) Validate() error {
+	size, err := resource.ParseQuantity(o.Size)
+	if err != nil {
+		return fmt.Errorf("invalid size %q: %v", o.Size, err)
+	}
+	if min := resource.MustParse(MinSize); size.Cmp(min) < 0 {
+		return fmt.Errorf("size %s is too small, the scratch PVC needs at least %s", o.Size, MinSize)
+	}
+	if err := o.Pod.Validate(); err != nil {
+		return fmt.Errorf("invalid pod settings: %v", err)
+	}
+	return nil
+}
+
+// Run provisions a scratch PVC, runs the fio profiles against it in a pod and
+// returns the parsed report. The PVC and pod are always deleted afterwards,
+// including when ctx is cancelled, e.g. on SIGINT.
+func Run(ctx context.Context, client *k8s.Client, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	size := resource.MustParse(opts.Size)
+
+	suffix := fmt.Sprintf("%d", time.Now().Unix())
+	claim := scratchClaim("pvc-bench-"+suffix, opts, size)
+	pod, err := benchPod("pvc-bench-"+suffix, claim.Name, opts, size)
+	if err != nil {
+		return nil, err
+	}
+
+	pvcs := client.Clientset.CoreV1().PersistentVolumeClaims(opts.Namespace)
+	pods := client.Clientset.CoreV1().Pods(opts.Namespace)
+
+	// Cleanup is registered before each create and doesn't use ctx, so that an
+	// interrupted create or a cancelled run still deletes what was created
+	defer func() {
+		err := pvcs.Delete(context.TODO(), claim.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("Warning: could not delete scratch PVC %s: %v", claim.Name, err)
+		}
+	}()
+	if _, err := pvcs.Create(ctx, claim, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("error creating scratch PVC: %v", err)
+	}
+
+	defer func() {
+		err := pods.Delete(context.TODO(), pod.Name, metav1.DeleteOptions{GracePeriodSeconds: int64Ptr(0)})
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("Warning: could not delete benchmark pod %s: %v", pod.Name, err)
+		}
+	}()
+	if _, err := pods.Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("error creating benchmark pod: %v", err)
+	}
+
+	// Leave time for provisioning and scheduling on top of the fio runtime
+	timeout := 5*time.Minute + time.Duration(len(opts.Profiles))*(opts.Runtime+30*time.Second)
+	log.Printf("Running %d fio profiles in pod %s (this takes about %s)...",
+		len(opts.Profiles), pod.Name, time.Duration(len(opts.Profiles))*opts.Runtime)
+	if err := waitForPodCompletion(ctx, client, opts.Namespace, pod.Name, timeout); err != nil {
+		return nil, err
+	}
+
+	logs, err := pods.GetLogs(pod.Name, &corev1.PodLogOptions{}).Do(ctx).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("error getting benchmark logs: %v", err)
+	}
+
+	results, err := parseLogs(string(logs))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{
+		StorageClass: opts.StorageClass,
+		Size:         opts.Size,
+		Results:      results,
+	}, nil
+}
+
+// scratchClaim builds the temporary PVC the benchmark runs against
+func scratchClaim(name string, opts Options, size resource.Quantity) *corev1.PersistentVolumeClaim {
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: opts.Namespace,
+			Labels:    map[string]string{"app": "pvc-bench"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if opts.StorageClass != "" {
+		claim.Spec.StorageClassName = &opts.StorageClass
+	}
+	return claim
+}
+
+// benchPod builds the pod that runs every profile and prints delimited fio JSON
+// to its logs. fio writes its JSON to a file that is printed afterwards, so that
+// its notices on stdout and stderr can't end up inside the JSON.
+func benchPod(name, claimName string, opts Options, size resource.Quantity) (*corev1.Pod, error) {
+	// Leave headroom for the filesystem overhead of the scratch volume
+	fileSize := fmt.Sprintf("%dM", size.Value()*8/10/(1024*1024))
+
+	var script []string
+	for _, p := range opts.Profiles {
+		output := "/data/" + p.Name + ".json"
+		script = append(script,
+			p.command("/data/fio.test", fileSize, output, opts.Runtime),
+			"echo FIO_BEGIN "+p.Name,
+			"cat "+output,
+			"echo",
+			"echo FIO_END "+p.Name,
+		)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: opts.Namespace,
+			Labels:    map[string]string{"app": "pvc-bench"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:    "fio",
+					Command: []string{"sh", "-c", "set -e\n" + strings.Join(script, "\n")},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "scratch", MountPath: "/data"},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "scratch",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+					},
+				},
+			},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+	if err := opts.Pod.Apply(&pod.Spec, &pod.Spec.Containers[0]); err != nil {
+		return nil, fmt.Errorf("invalid pod settings: %v", err)
+	}
+	// A fresh volume is owned by root, so a non-root fio needs group access to it
+	if pod.Spec.SecurityContext.RunAsUser != nil {
+		pod.Spec.SecurityContext.FSGroup = pod.Spec.SecurityContext.RunAsUser
+	}
+	return pod, nil
+}
+
+// waitForPodCompletion waits until the pod has succeeded, failing if it fails,
+// times out or ctx is cancelled
+func waitForPodCompletion(ctx context.Context, client *k8s.Client, namespace, podName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		pod, err := client.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("benchmark interrupted: %v", ctx.Err())
+			}
+			return fmt.Errorf("error getting benchmark pod: %v", err)
+		}
+
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			return nil
+		case corev1.PodFailed:
+			return fmt.Errorf("benchmark pod %s failed: %s", podName, pod.Status.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("benchmark interrupted: %v", ctx.Err())
+		case <-time.After(5 * time.Second):
+		}
+	}
+	return fmt.Errorf("timed out after %s waiting for benchmark pod %s", timeout, podName)
+}
+
+// int64Ptr returns a pointer to an int64
+func int64Ptr(i int64) *int64 {
+	return &i
+}
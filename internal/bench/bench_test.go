@@ -0,0 +1,63 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		size    string
+		wantErr bool
+	}{
+		{"10Gi", false},
+		{"64Mi", false},
+		{"1Mi", true},
+		{"63Mi", true},
+		{"lots", true},
+	}
+
+	for _, tt := range tests {
+		opts := Options{Size: tt.size, Pod: DefaultPodSettings()}
+		if err := opts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate() with size %s error = %v, wantErr %v", tt.size, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBenchPod(t *testing.T) {
+	opts := Options{
+		Namespace: "bench",
+		Runtime:   30 * time.Second,
+		Profiles:  DefaultProfiles[:1],
+		Pod:       DefaultPodSettings(),
+	}
+	pod, err := benchPod("pvc-bench-1", "pvc-bench-1", opts, resource.MustParse("1Gi"))
+	if err != nil {
+		t.Fatalf("benchPod() unexpected error: %v", err)
+	}
+
+	// The pod passes the "restricted" Pod Security Standard like monitor pods
+	sc := pod.Spec.SecurityContext
+	if sc == nil || sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot || sc.SeccompProfile == nil {
+		t.Errorf("pod security context = %+v, want the restricted defaults", sc)
+	}
+	if sc != nil && (sc.FSGroup == nil || sc.RunAsUser == nil || *sc.FSGroup != *sc.RunAsUser) {
+		t.Errorf("fsGroup = %v, want the runAsUser so that fio can write to the volume", sc.FSGroup)
+	}
+	container := pod.Spec.Containers[0]
+	if container.Image != DefaultImage || container.SecurityContext == nil || container.Resources.Limits.Cpu().String() != "2" {
+		t.Errorf("container = %+v, want the fio image, security context and resources", container)
+	}
+
+	// fio writes its JSON to a file, which alone is printed between the markers
+	script := container.Command[2]
+	for _, want := range []string{"--size=819M", "--output=/data/randread-4k.json", "echo FIO_BEGIN randread-4k\ncat /data/randread-4k.json\necho\necho FIO_END randread-4k"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script is missing %q:\n%s", want, script)
+		}
+	}
+}
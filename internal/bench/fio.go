@@ -0,0 +1,175 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Profile describes a single fio job
+type Profile struct {
+	Name      string
+	RW        string // fio --rw value
+	BlockSize string
+	IODepth   int
+	RWMixRead int // percentage of reads for mixed workloads, 0 if not mixed
+}
+
+// DefaultProfiles are the fio jobs run when no profile is selected
+var DefaultProfiles = []Profile{
+	{Name: "randread-4k", RW: "randread", BlockSize: "4k", IODepth: 32},
+	{Name: "randwrite-4k", RW: "randwrite", BlockSize: "4k", IODepth: 32},
+	{Name: "seqread-1m", RW: "read", BlockSize: "1m", IODepth: 8},
+	{Name: "seqwrite-1m", RW: "write", BlockSize: "1m", IODepth: 8},
+	{Name: "mixed-70-30", RW: "randrw", BlockSize: "4k", IODepth: 32, RWMixRead: 70},
+}
+
+// SelectProfiles returns the default profiles matching the comma-separated names,
+// or all of them when names is empty
+func SelectProfiles(names string) ([]Profile, error) {
+	if names == "" {
+		return DefaultProfiles, nil
+	}
+
+	var selected []Profile
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, p := range DefaultProfiles {
+			if p.Name == name {
+				selected = append(selected, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// command returns the fio command line for the profile, writing its JSON report to output
+func (p Profile) command(filename, size, output string, runtime time.Duration) string {
+	args := []string{
+		"fio",
+		"--name=" + p.Name,
+		"--filename=" + filename,
+		"--size=" + size,
+		"--rw=" + p.RW,
+		"--bs=" + p.BlockSize,
+		fmt.Sprintf("--iodepth=%d", p.IODepth),
+		"--ioengine=libaio",
+		"--direct=1",
+		fmt.Sprintf("--runtime=%d", int(runtime.Seconds())),
+		"--time_based",
+		"--group_reporting",
+		"--output-format=json",
+		"--output=" + output,
+	}
+	if p.RWMixRead > 0 {
+		args = append(args, fmt.Sprintf("--rwmixread=%d", p.RWMixRead))
+	}
+	return strings.Join(args, " ")
+}
+
+// Stats holds the results of one I/O direction of a fio job
+type Stats struct {
+	IOPS           float64
+	BandwidthBytes int64 // bytes per second
+	P50            time.Duration
+	P99            time.Duration
+	P999           time.Duration
+}
+
+// Result holds the results of a single profile
+type Result struct {
+	Profile string
+	Read    Stats
+	Write   Stats
+}
+
+// Report holds the results of a benchmark run
+type Report struct {
+	StorageClass string
+	Size         string
+	Results      []Result
+}
+
+// fioOutput is the subset of fio's JSON output we use
+type fioOutput struct {
+	Jobs []struct {
+		JobName string    `json:"jobname"`
+		Read    fioIOStat `json:"read"`
+		Write   fioIOStat `json:"write"`
+	} `json:"jobs"`
+}
+
+// fioIOStat is one I/O direction in fio's JSON output
+type fioIOStat struct {
+	IOPS    float64 `json:"iops"`
+	BWBytes int64   `json:"bw_bytes"`
+	ClatNs  struct {
+		Percentile map[string]float64 `json:"percentile"`
+	} `json:"clat_ns"`
+}
+
+// stats converts fio's I/O direction output into Stats
+func (s fioIOStat) stats() Stats {
+	percentile := func(key string) time.Duration {
+		return time.Duration(s.ClatNs.Percentile[key])
+	}
+	return Stats{
+		IOPS:           s.IOPS,
+		BandwidthBytes: s.BWBytes,
+		P50:            percentile("50.000000"),
+		P99:            percentile("99.000000"),
+		P999:           percentile("99.900000"),
+	}
+}
+
+// parseFioOutput parses the JSON output of a single fio run
+func parseFioOutput(profile string, raw []byte) (Result, error) {
+	var out fioOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return Result{}, fmt.Errorf("error parsing fio output for %s: %v", profile, err)
+	}
+	if len(out.Jobs) == 0 {
+		return Result{}, fmt.Errorf("fio output for %s has no jobs", profile)
+	}
+
+	job := out.Jobs[0]
+	return Result{
+		Profile: profile,
+		Read:    job.Read.stats(),
+		Write:   job.Write.stats(),
+	}, nil
+}
+
+// parseLogs extracts every FIO_BEGIN/FIO_END delimited fio run from the pod logs
+func parseLogs(logs string) ([]Result, error) {
+	var results []Result
+	lines := strings.Split(logs, "\n")
+	for i := 0; i < len(lines); i++ {
+		profile, ok := strings.CutPrefix(lines[i], "FIO_BEGIN ")
+		if !ok {
+			continue
+		}
+
+		var body []string
+		for i++; i < len(lines) && lines[i] != "FIO_END "+profile; i++ {
+			body = append(body, lines[i])
+		}
+		if i == len(lines) {
+			return nil, fmt.Errorf("fio output for %s is truncated", profile)
+		}
+
+		result, err := parseFioOutput(profile, []byte(strings.Join(body, "\n")))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
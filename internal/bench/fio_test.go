@@ -0,0 +1,80 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleFioJSON = `{
+  "fio version": "fio-3.36",
+  "jobs": [
+    {
+      "jobname": "mixed-70-30",
+      "read": {
+        "iops": 7012.5,
+        "bw_bytes": 28723200,
+        "clat_ns": {"percentile": {"50.000000": 350208, "99.000000": 1204224, "99.900000": 4227072}}
+      },
+      "write": {
+        "iops": 3004.1,
+        "bw_bytes": 12304793,
+        "clat_ns": {"percentile": {"50.000000": 610304, "99.000000": 2506752, "99.900000": 8847360}}
+      }
+    }
+  ]
+}`
+
+func TestParseLogs(t *testing.T) {
+	logs := strings.Join([]string{
+		"FIO_BEGIN mixed-70-30",
+		sampleFioJSON,
+		"FIO_END mixed-70-30",
+		"",
+	}, "\n")
+
+	results, err := parseLogs(logs)
+	if err != nil {
+		t.Fatalf("parseLogs() unexpected error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("parseLogs() returned %d results, want 1", len(results))
+	}
+
+	r := results[0]
+	if r.Profile != "mixed-70-30" {
+		t.Errorf("Profile = %q, want mixed-70-30", r.Profile)
+	}
+	if r.Read.IOPS != 7012.5 || r.Read.BandwidthBytes != 28723200 {
+		t.Errorf("Read = %+v", r.Read)
+	}
+	if r.Read.P50 != 350208*time.Nanosecond || r.Read.P999 != 4227072*time.Nanosecond {
+		t.Errorf("Read latency = %v/%v", r.Read.P50, r.Read.P999)
+	}
+	if r.Write.P99 != 2506752*time.Nanosecond {
+		t.Errorf("Write p99 = %v", r.Write.P99)
+	}
+
+	if _, err := parseLogs("FIO_BEGIN seqread-1m\n{}"); err == nil {
+		t.Errorf("parseLogs() expected error for truncated output")
+	}
+	if _, err := parseLogs("FIO_BEGIN x\nnot json\nFIO_END x"); err == nil {
+		t.Errorf("parseLogs() expected error for invalid JSON")
+	}
+}
+
+func TestSelectProfiles(t *testing.T) {
+	all, err := SelectProfiles("")
+	if err != nil || len(all) != len(DefaultProfiles) {
+		t.Errorf("SelectProfiles(\"\") = %d profiles, %v", len(all), err)
+	}
+
+	some, err := SelectProfiles("randread-4k, mixed-70-30")
+	if err != nil || len(some) != 2 || some[1].RWMixRead != 70 {
+		t.Errorf("SelectProfiles() = %+v, %v", some, err)
+	}
+
+	if _, err := SelectProfiles("nope"); err == nil {
+		t.Errorf("SelectProfiles(\"nope\") expected error")
+	}
+}
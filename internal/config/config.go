@@ -0,0 +1,337 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/joseEnrique/pvcusage/internal/alert"
+	"github.com/joseEnrique/pvcusage/internal/cost"
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// Config is the contents of the configuration file
+type Config struct {
+	// DefaultProfile is used when --profile isn't given
+	DefaultProfile string              `yaml:"defaultProfile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+	// Pricing prices storage per StorageClass for the cost command
+	Pricing cost.Pricing `yaml:"pricing"`
+}
+
+// Profile holds default values for command flags. Flags given on the command
+// line override them.
+type Profile struct {
+	Filter      string   `yaml:"filter"`
+	Sort        string   `yaml:"sort"`
+	Top         int      `yaml:"top"`
+	GroupBy     string   `yaml:"groupBy"`
+	Warn        *float64 `yaml:"warn"`
+	Crit        *float64 `yaml:"crit"`
+	Summary     bool     `yaml:"summary"`     // totals footer of the usage table
+	Columns     []string `yaml:"columns"`     // optional columns of the usage and watch tables
+	Output      string   `yaml:"output"`      // output of usage
+	WatchOutput string   `yaml:"watchOutput"` // output of watch
+	Interval    string   `yaml:"interval"`    // refresh interval of watch and serve
+
+	// Perf holds the monitor pod settings, in the format of perf --pod-config
+	Perf yaml.Node `yaml:"perf"`
+	// Alerts are the webhooks that receive the events of watch -o events
+	Alerts []alert.Sink `yaml:"alerts"`
+
+	perfSettings *k8s.PodSettings
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/pvcusage/config.yaml, or ~/.config/pvcusage/config.yaml
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "pvcusage", "config.yaml")
+}
+
+// Load reads and validates a configuration file
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %v", err)
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+// Parse decodes and validates a configuration. Errors mention the line of the
+// offending value.
+func Parse(data []byte) (*Config, error) {
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, yamlError(err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, yamlError(err)
+	}
+	if err := validate(&root, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Profile returns the named profile, or the default profile if name is empty.
+// It returns nil if neither is set.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	return profile, nil
+}
+
+// Flags returns the profile's values keyed by command flag name. Only the
+// values set in the profile are included.
+func (p *Profile) Flags() map[string]string {
+	flags := make(map[string]string)
+	set := func(name, value string) {
+		if value != "" {
+			flags[name] = value
+		}
+	}
+	set("filter", p.Filter)
+	set("sort", p.Sort)
+	if p.Top > 0 {
+		set("top", strconv.Itoa(p.Top))
+	}
+	set("group-by", p.GroupBy)
+	if p.Warn != nil {
+		set("warn", strconv.FormatFloat(*p.Warn, 'f', -1, 64))
+	}
+	if p.Crit != nil {
+		set("crit", strconv.FormatFloat(*p.Crit, 'f', -1, 64))
+	}
+	if p.Summary {
+		set("summary", "true")
+	}
+	set("columns", strings.Join(p.Columns, ","))
+	set("interval", p.Interval)
+	return flags
+}
+
+// PerfSettings returns the monitor pod settings of the profile, or nil if it has none
+func (p *Profile) PerfSettings() *k8s.PodSettings {
+	return p.perfSettings
+}
+
+// yamlError reformats decoding errors as "line N: message"
+func yamlError(err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return errors.New(strings.Join(typeErr.Errors, "; "))
+	}
+	return errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+// lineError prefixes an error with the line of the node it concerns
+func lineError(node *yaml.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", node.Line, fmt.Sprintf(format, args...))
+}
+
+// validate checks the values of every profile, walking the YAML nodes so that
+// errors can report their line
+func validate(root *yaml.Node, config *Config) error {
+	if len(root.Content) == 0 {
+		return nil
+	}
+	document := root.Content[0]
+
+	for i := 0; i+1 < len(document.Content); i += 2 {
+		key, value := document.Content[i], document.Content[i+1]
+		switch key.Value {
+		case "defaultProfile":
+			if _, ok := config.Profiles[value.Value]; !ok {
+				return lineError(value, "default profile %q is not defined", value.Value)
+			}
+		case "pricing":
+			if err := validatePricing(value, config.Pricing); err != nil {
+				return err
+			}
+		case "profiles":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name := value.Content[j].Value
+				if err := validateProfile(value.Content[j+1], config.Profiles[name]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateProfile checks the values of one profile node
+func validateProfile(node *yaml.Node, profile *Profile) error {
+	if profile == nil {
+		return lineError(node, "profile is empty")
+	}
+
+	var warn, crit *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch key.Value {
+		case "filter":
+			_, _, err = pvc.ParseFilter(value.Value)
+		case "sort":
+			// Sorting nothing only validates the key
+			err = pvc.SortUsages(nil, value.Value)
+		case "groupBy":
+			_, err = pvc.GroupUsages(nil, value.Value)
+		case "top":
+			if profile.Top < 0 {
+				err = fmt.Errorf("top must not be negative")
+			}
+		case "warn":
+			if profile.Warn != nil {
+				warn = value
+				err = validatePercentage(*profile.Warn)
+			}
+		case "crit":
+			if profile.Crit != nil {
+				crit = value
+				err = validatePercentage(*profile.Crit)
+			}
+		case "output":
+			if display.IsTemplateOutput(value.Value) {
+				_, err = display.NewUsagePrinter(value.Value)
+			} else {
+				err = validateChoice(value.Value, "table", "json", "yaml")
+			}
+		case "columns":
+			for j, name := range profile.Columns {
+				if !display.IsOptionalColumn(name) {
+					return lineError(value.Content[j], "invalid columns: unknown column %q (expected %s)", name, strings.Join(display.OptionalColumns, ", "))
+				}
+			}
+		case "watchOutput":
+			err = validateChoice(value.Value, "table", "events")
+		case "interval":
+			var interval time.Duration
+			interval, err = time.ParseDuration(value.Value)
+			if err == nil && interval <= 0 {
+				err = fmt.Errorf("interval must be positive")
+			}
+		case "perf":
+			if err := parsePerf(value, profile); err != nil {
+				return err
+			}
+		case "alerts":
+			for j, sink := range profile.Alerts {
+				if err := sink.Validate(); err != nil {
+					return lineError(value.Content[j], "invalid alert sink: %v", err)
+				}
+			}
+		}
+		if err != nil {
+			return lineError(value, "invalid %s: %v", key.Value, err)
+		}
+	}
+
+	if warn != nil && crit != nil && *profile.Warn >= *profile.Crit {
+		return lineError(crit, "crit (%v) must be greater than warn (%v)", *profile.Crit, *profile.Warn)
+	}
+	return nil
+}
+
+// validatePricing checks that no price is negative
+func validatePricing(node *yaml.Node, pricing cost.Pricing) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case "default":
+			if pricing.Default == nil {
+				continue
+			}
+			if err := pricing.Default.Validate(); err != nil {
+				return lineError(value, "invalid default price: %v", err)
+			}
+		case "storageClasses":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name := value.Content[j].Value
+				if err := pricing.StorageClasses[name].Validate(); err != nil {
+					return lineError(value.Content[j+1], "invalid price of %s: %v", name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parsePerf decodes the perf settings one key at a time, so that errors point
+// at the offending key
+func parsePerf(node *yaml.Node, profile *Profile) error {
+	if node.Kind != yaml.MappingNode {
+		return lineError(node, "perf must be a mapping of pod settings")
+	}
+
+	settings := k8s.DefaultPodSettings()
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		data, err := yaml.Marshal(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}})
+		if err != nil {
+			return lineError(key, "%v", err)
+		}
+		if err := sigsyaml.UnmarshalStrict(data, &settings); err != nil {
+			return lineError(key, "invalid perf setting %s: %v", key.Value, err)
+		}
+	}
+	if err := settings.Validate(); err != nil {
+		return lineError(node, "invalid perf settings: %v", err)
+	}
+
+	profile.perfSettings = &settings
+	return nil
+}
+
+// validatePercentage checks that a threshold is between 0 and 100
+func validatePercentage(value float64) error {
+	if value < 0 || value > 100 {
+		return fmt.Errorf("%v is not a percentage between 0 and 100", value)
+	}
+	return nil
+}
+
+// validateChoice checks that value is one of the choices
+func validateChoice(value string, choices ...string) error {
+	for _, choice := range choices {
+		if value == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", value, strings.Join(choices, ", "))
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const validConfig = `defaultProfile: dev
+profiles:
+  dev:
+    filter: ">50"
+    sort: used
+    top: 10
+    summary: true
+    columns: [type, workload, snapshots]
+    interval: 10s
+  prod:
+    warn: 70
+    crit: 85
+    output: custom-columns=NS:.namespace,PVC:.pvc,FREE:.available
+    watchOutput: events
+    perf:
+      image: registry.local/netshoot:v0.13
+      cpuLimit: 200m
+      imagePullSecrets: [registry-local]
+    alerts:
+    - webhook: https://hooks.example.com/pvc
+      headers: {Authorization: Bearer token}
+    - webhook: http://alertmanager-bridge:8080
+      events: [threshold_changed, removed]
+pricing:
+  currency: USD
+  default:
+    perGiBMonth: 0.10
+  storageClasses:
+    gp3:
+      perGiBMonth: 0.08
+      iops: {provisioned: 6000, included: 3000, perUnitMonth: 0.005}
+`
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(validConfig))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	dev, err := config.Profile("")
+	if err != nil {
+		t.Fatalf("Profile(\"\") unexpected error: %v", err)
+	}
+	flags := dev.Flags()
+	if flags["filter"] != ">50" || flags["sort"] != "used" || flags["top"] != "10" || flags["summary"] != "true" || flags["interval"] != "10s" {
+		t.Errorf("dev flags = %v", flags)
+	}
+	if flags["columns"] != "type,workload,snapshots" {
+		t.Errorf("dev columns = %q", flags["columns"])
+	}
+	if _, ok := flags["warn"]; ok {
+		t.Errorf("dev flags include unset warn")
+	}
+	if dev.PerfSettings() != nil {
+		t.Errorf("dev has perf settings, want none")
+	}
+
+	prod, err := config.Profile("prod")
+	if err != nil {
+		t.Fatalf("Profile(\"prod\") unexpected error: %v", err)
+	}
+	if flags := prod.Flags(); flags["warn"] != "70" || flags["crit"] != "85" {
+		t.Errorf("prod flags = %v", flags)
+	}
+	if prod.Output != "custom-columns=NS:.namespace,PVC:.pvc,FREE:.available" || prod.WatchOutput != "events" {
+		t.Errorf("prod output = %q, watchOutput = %q", prod.Output, prod.WatchOutput)
+	}
+	perf := prod.PerfSettings()
+	if perf == nil || perf.Image != "registry.local/netshoot:v0.13" || perf.CPULimit != "200m" || perf.CPURequest != "50m" {
+		t.Errorf("prod perf settings = %+v", perf)
+	}
+
+	if len(prod.Alerts) != 2 || prod.Alerts[0].Headers["Authorization"] != "Bearer token" || len(prod.Alerts[1].Events) != 2 {
+		t.Errorf("prod alerts = %+v", prod.Alerts)
+	}
+
+	if gp3 := config.Pricing.StorageClasses["gp3"]; config.Pricing.Currency != "USD" || gp3.PerGiBMonth != 0.08 ||
+		gp3.IOPS == nil || gp3.IOPS.Provisioned != 6000 || config.Pricing.Default.PerGiBMonth != 0.10 {
+		t.Errorf("pricing = %+v", config.Pricing)
+	}
+
+	if _, err := config.Profile("staging"); err == nil {
+		t.Errorf("Profile(\"staging\") expected error")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"unknown field", "profiles:\n  dev:\n    fliter: '>50'\n", "line 3: field fliter not found"},
+		{"wrong type", "profiles:\n  dev:\n    top: many\n", "line 3:"},
+		{"invalid filter", "profiles:\n  dev:\n    filter: '~50'\n", "line 3: invalid filter"},
+		{"invalid sort", "profiles:\n  dev:\n    top: 5\n    sort: node\n", "line 4: invalid sort"},
+		{"invalid output", "profiles:\n  dev:\n    output: csv\n", "line 3: invalid output"},
+		{"invalid template", "profiles:\n  dev:\n    output: 'jsonpath={.items['\n", "line 3: invalid output"},
+		{"invalid interval", "profiles:\n  dev:\n    interval: 0s\n", "line 3: invalid interval"},
+		{"thresholds", "profiles:\n  dev:\n    warn: 90\n    crit: 80\n", "line 4: crit (80) must be greater than warn (90)"},
+		{"percentage", "profiles:\n  dev:\n    warn: 120\n", "line 3: invalid warn"},
+		{"unknown perf setting", "profiles:\n  dev:\n    perf:\n      image: x\n      cpu: 1\n", "line 5: invalid perf setting cpu"},
+		{"invalid perf quantity", "profiles:\n  dev:\n    perf:\n      cpuLimit: lots\n", "line 4: invalid perf settings"},
+		{"unknown column", "profiles:\n  dev:\n    columns:\n    - workload\n    - node\n", "line 5: invalid columns: unknown column \"node\""},
+		{"invalid webhook", "profiles:\n  dev:\n    alerts:\n    - webhook: https://ok\n    - webhook: hooks.local\n", "line 5: invalid alert sink: webhook"},
+		{"unknown alert event", "profiles:\n  dev:\n    alerts:\n    - webhook: https://ok\n      events: [full]\n", "line 4: invalid alert sink: event"},
+		{"missing default", "defaultProfile: prod\nprofiles:\n  dev:\n    top: 5\n", "line 1: default profile \"prod\" is not defined"},
+		{"negative price", "pricing:\n  storageClasses:\n    gp3:\n      perGiBMonth: -1\n", "line 4: invalid price of gp3"},
+		{"negative tier", "pricing:\n  default:\n    iops: {provisioned: -5}\n", "line 3: invalid default price: iops"},
+		{"unknown price field", "pricing:\n  default:\n    perGBMonth: 1\n", "line 3: field perGBMonth not found"},
+		{"syntax", "profiles:\n  dev:\n    top: [5\n", "line"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.config))
+		if err == nil {
+			t.Errorf("%s: Parse() expected error", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: Parse() error = %q, want it to contain %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	config, err := Parse(nil)
+	if err != nil {
+		t.Fatalf("Parse(nil) unexpected error: %v", err)
+	}
+	if profile, err := config.Profile(""); profile != nil || err != nil {
+		t.Errorf("Profile(\"\") = %v, %v, want nil, nil", profile, err)
+	}
+}
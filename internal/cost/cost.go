@@ -0,0 +1,192 @@
+package cost
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// bytesPerGiB converts byte sizes to the GiB unit of prices
+const bytesPerGiB = 1 << 30
+
+// Pricing is the monthly price of storage per StorageClass
+type Pricing struct {
+	Currency       string           `yaml:"currency" json:"currency,omitempty"`
+	Default        *Price           `yaml:"default" json:"default,omitempty"` // for StorageClasses not listed
+	StorageClasses map[string]Price `yaml:"storageClasses" json:"storageClasses,omitempty"`
+}
+
+// Price is the monthly price of one volume of a StorageClass
+type Price struct {
+	PerGiBMonth float64 `yaml:"perGiBMonth" json:"perGiBMonth"`
+	IOPS        *Tier   `yaml:"iops" json:"iops,omitempty"`
+	Throughput  *Tier   `yaml:"throughput" json:"throughput,omitempty"` // in MiB/s
+}
+
+// Tier prices the IOPS or throughput provisioned per volume beyond what the
+// storage price includes
+type Tier struct {
+	Provisioned  float64 `yaml:"provisioned" json:"provisioned"`
+	Included     float64 `yaml:"included" json:"included"`
+	PerUnitMonth float64 `yaml:"perUnitMonth" json:"perUnitMonth"`
+}
+
+// IsZero reports whether no pricing is configured
+func (p Pricing) IsZero() bool {
+	return p.Default == nil && len(p.StorageClasses) == 0
+}
+
+// Validate checks that no price is negative
+func (p Price) Validate() error {
+	if p.PerGiBMonth < 0 {
+		return fmt.Errorf("perGiBMonth must not be negative")
+	}
+	tiers := []struct {
+		name string
+		tier *Tier
+	}{{"iops", p.IOPS}, {"throughput", p.Throughput}}
+	for _, t := range tiers {
+		if t.tier != nil && (t.tier.Provisioned < 0 || t.tier.Included < 0 || t.tier.PerUnitMonth < 0) {
+			return fmt.Errorf("%s values must not be negative", t.name)
+		}
+	}
+	return nil
+}
+
+// price returns the price of a StorageClass, falling back to the default price
+func (p Pricing) price(storageClass string) (Price, bool) {
+	if price, ok := p.StorageClasses[storageClass]; ok {
+		return price, true
+	}
+	if p.Default != nil {
+		return *p.Default, true
+	}
+	return Price{}, false
+}
+
+// monthly returns the cost of the tier's billable units
+func (t *Tier) monthly() float64 {
+	if t == nil || t.Provisioned <= t.Included {
+		return 0
+	}
+	return (t.Provisioned - t.Included) * t.PerUnitMonth
+}
+
+// Cost is the monthly cost of a PVC. WastedCost is the price of the
+// provisioned space that holds no data.
+type Cost struct {
+	Namespace     string  `json:"namespace"`
+	PVC           string  `json:"pvc"`
+	StorageClass  string  `json:"storageClass,omitempty"`
+	CapacityBytes int64   `json:"capacityBytes"`
+	UsedBytes     int64   `json:"usedBytes"`
+	Mounted       bool    `json:"mounted"` // unmounted PVCs count as entirely unused
+	Cost          float64 `json:"cost"`
+	WastedCost    float64 `json:"wastedCost"`
+	Unpriced      bool    `json:"unpriced,omitempty"` // the StorageClass has no price
+}
+
+// Estimate prices the provisioned PVCs. Claims that aren't bound to a volume
+// yet cost nothing and are left out.
+func Estimate(sizes []k8s.ClaimSize, usages []pvc.Usage, pricing Pricing) []Cost {
+	used := make(map[k8s.ClaimKey]int64)
+	for _, u := range usages {
+		used[k8s.ClaimKey{Namespace: u.Namespace, Name: u.PVC}] = u.UsedBytes
+	}
+
+	var costs []Cost
+	for _, size := range sizes {
+		// Volumes are billed by their provisioned size
+		capacity := size.StatusBytes
+		if capacity == 0 {
+			capacity = size.VolumeBytes
+		}
+		if capacity == 0 {
+			continue
+		}
+
+		c := Cost{
+			Namespace:     size.Namespace,
+			PVC:           size.PVC,
+			StorageClass:  size.StorageClass,
+			CapacityBytes: capacity,
+		}
+		c.UsedBytes, c.Mounted = used[k8s.ClaimKey{Namespace: size.Namespace, Name: size.PVC}]
+
+		price, ok := pricing.price(size.StorageClass)
+		if !ok {
+			c.Unpriced = true
+			costs = append(costs, c)
+			continue
+		}
+		unused := capacity - c.UsedBytes
+		if unused < 0 {
+			unused = 0
+		}
+		c.Cost = float64(capacity)/bytesPerGiB*price.PerGiBMonth + price.IOPS.monthly() + price.Throughput.monthly()
+		c.WastedCost = float64(unused) / bytesPerGiB * price.PerGiBMonth
+		costs = append(costs, c)
+	}
+	return costs
+}
+
+// NamespaceCost is the monthly cost of the PVCs of a namespace. When some
+// PVCs have no price, Cost and WastedCost only cover the priced ones.
+type NamespaceCost struct {
+	Namespace     string  `json:"namespace"`
+	PVCs          int     `json:"pvcs"`
+	CapacityBytes int64   `json:"capacityBytes"`
+	UsedBytes     int64   `json:"usedBytes"`
+	Cost          float64 `json:"cost"`
+	WastedCost    float64 `json:"wastedCost"`
+	Unpriced      int     `json:"unpriced,omitempty"` // PVCs left out of the costs
+}
+
+// Incomplete reports whether some PVCs of the namespace have no price
+func (n NamespaceCost) Incomplete() bool {
+	return n.Unpriced > 0
+}
+
+// RollupNamespaces sums costs per namespace, ordered by cost (descending)
+func RollupNamespaces(costs []Cost) []NamespaceCost {
+	var rollup []NamespaceCost
+	index := make(map[string]int)
+	for _, c := range costs {
+		i, ok := index[c.Namespace]
+		if !ok {
+			i = len(rollup)
+			index[c.Namespace] = i
+			rollup = append(rollup, NamespaceCost{Namespace: c.Namespace})
+		}
+		n := &rollup[i]
+		n.PVCs++
+		n.CapacityBytes += c.CapacityBytes
+		n.UsedBytes += c.UsedBytes
+		n.Cost += c.Cost
+		n.WastedCost += c.WastedCost
+		if c.Unpriced {
+			n.Unpriced++
+		}
+	}
+
+	sort.SliceStable(rollup, func(i, j int) bool {
+		return rollup[i].Cost > rollup[j].Cost
+	})
+	return rollup
+}
+
+// UnpricedClasses returns the StorageClasses of costs that have no price
+func UnpricedClasses(costs []Cost) []string {
+	seen := make(map[string]bool)
+	var classes []string
+	for _, c := range costs {
+		if c.Unpriced && !seen[c.StorageClass] {
+			seen[c.StorageClass] = true
+			classes = append(classes, c.StorageClass)
+		}
+	}
+	sort.Strings(classes)
+	return classes
+}
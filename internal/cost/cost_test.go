@@ -0,0 +1,80 @@
+package cost
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func TestEstimate(t *testing.T) {
+	pricing := Pricing{
+		Default: &Price{PerGiBMonth: 0.1},
+		StorageClasses: map[string]Price{
+			"gp3": {
+				PerGiBMonth: 0.08,
+				IOPS:        &Tier{Provisioned: 4000, Included: 3000, PerUnitMonth: 0.005},
+				Throughput:  &Tier{Provisioned: 125, Included: 125, PerUnitMonth: 0.04},
+			},
+		},
+	}
+	sizes := []k8s.ClaimSize{
+		{Namespace: "kafka", PVC: "data-0", StorageClass: "gp3", StatusBytes: 100 << 30},
+		{Namespace: "kafka", PVC: "idle", StorageClass: "standard", VolumeBytes: 10 << 30},
+		{Namespace: "web", PVC: "pending", StorageClass: "gp3", RequestedBytes: 1 << 30},
+	}
+	usages := []pvc.Usage{{Namespace: "kafka", PVC: "data-0", UsedBytes: 75 << 30}}
+
+	expected := []Cost{
+		{Namespace: "kafka", PVC: "data-0", StorageClass: "gp3", CapacityBytes: 100 << 30, UsedBytes: 75 << 30,
+			Mounted: true, Cost: 100*0.08 + 1000*0.005, WastedCost: 25 * 0.08},
+		{Namespace: "kafka", PVC: "idle", StorageClass: "standard", CapacityBytes: 10 << 30,
+			Cost: 10 * 0.1, WastedCost: 10 * 0.1},
+	}
+	if costs := Estimate(sizes, usages, pricing); !reflect.DeepEqual(costs, expected) {
+		t.Errorf("Estimate() = %+v, want %+v", costs, expected)
+	}
+
+	pricing.Default = nil
+	costs := Estimate(sizes, usages, pricing)
+	if !costs[1].Unpriced || costs[1].Cost != 0 {
+		t.Errorf("Estimate() without a default price = %+v, want idle unpriced", costs[1])
+	}
+	if classes := UnpricedClasses(costs); !reflect.DeepEqual(classes, []string{"standard"}) {
+		t.Errorf("UnpricedClasses() = %v, want [standard]", classes)
+	}
+}
+
+func TestRollupNamespaces(t *testing.T) {
+	costs := []Cost{
+		{Namespace: "web", CapacityBytes: 10, UsedBytes: 5, Cost: 1, WastedCost: 0.5},
+		{Namespace: "kafka", CapacityBytes: 100, UsedBytes: 20, Cost: 8, WastedCost: 6},
+		{Namespace: "web", CapacityBytes: 30, UsedBytes: 0, Cost: 3, WastedCost: 3},
+		{Namespace: "web", CapacityBytes: 50, UsedBytes: 0, Unpriced: true},
+	}
+	expected := []NamespaceCost{
+		{Namespace: "kafka", PVCs: 1, CapacityBytes: 100, UsedBytes: 20, Cost: 8, WastedCost: 6},
+		{Namespace: "web", PVCs: 3, CapacityBytes: 90, UsedBytes: 5, Cost: 4, WastedCost: 3.5, Unpriced: 1},
+	}
+	if rollup := RollupNamespaces(costs); !reflect.DeepEqual(rollup, expected) {
+		t.Errorf("RollupNamespaces() = %+v, want %+v", rollup, expected)
+	}
+}
+
+func TestPriceValidate(t *testing.T) {
+	tests := []struct {
+		price   Price
+		wantErr bool
+	}{
+		{Price{PerGiBMonth: 0.08, IOPS: &Tier{Provisioned: 3000, PerUnitMonth: 0.005}}, false},
+		{Price{PerGiBMonth: -1}, true},
+		{Price{Throughput: &Tier{Included: -125}}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.price.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.price, err, tt.wantErr)
+		}
+	}
+}
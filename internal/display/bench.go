@@ -0,0 +1,49 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/bench"
+)
+
+// ShowBenchReport displays the fio results of a benchmark run, one row per profile and direction
+func ShowBenchReport(report *bench.Report) {
+	storageClass := report.StorageClass
+	if storageClass == "" {
+		storageClass = "(default)"
+	}
+	fmt.Printf("StorageClass: %s  Size: %s\n\n", storageClass, report.Size)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Profile\tOp\tIOPS\tBandwidth\tp50\tp99\tp99.9")
+	for _, r := range report.Results {
+		for _, op := range []struct {
+			name  string
+			stats bench.Stats
+		}{{"read", r.Read}, {"write", r.Write}} {
+			// Skip the direction a profile doesn't exercise
+			if op.stats.IOPS == 0 && op.stats.BandwidthBytes == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.0f\t%s/s\t%s\t%s\t%s\n",
+				r.Profile, op.name, op.stats.IOPS, HumanizeBytes(op.stats.BandwidthBytes),
+				formatLatency(op.stats.P50), formatLatency(op.stats.P99), formatLatency(op.stats.P999))
+		}
+	}
+	w.Flush()
+}
+
+// formatLatency prints a latency with a precision that suits its magnitude
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%.0fus", float64(d)/float64(time.Microsecond))
+	}
+}
@@ -0,0 +1,61 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// ANSI codes of the usage states
+const (
+	colorOK       = "\033[32m"
+	colorWarning  = "\033[33m"
+	colorCritical = "\033[31m"
+	colorReset    = "\033[0m"
+)
+
+// ColorEnabled resolves a --color mode ("auto", "always" or "never"). In auto
+// mode, color is used when stdout is a terminal and NO_COLOR isn't set.
+func ColorEnabled(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		return term.IsTerminal(int(os.Stdout.Fd())), nil
+	default:
+		return false, fmt.Errorf("invalid color mode %q (expected auto, always or never)", mode)
+	}
+}
+
+// stateColor returns the ANSI code of a usage state
+func stateColor(state string) string {
+	switch state {
+	case pvc.StateCritical:
+		return colorCritical
+	case pvc.StateWarning:
+		return colorWarning
+	default:
+		return colorOK
+	}
+}
+
+// UsageBar draws a bar of width cells filled in proportion to percentage
+func UsageBar(percentage float64, width int) string {
+	filled := int(percentage/100*float64(width) + 0.5)
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > width {
+		filled = width
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
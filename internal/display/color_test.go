@@ -0,0 +1,115 @@
+package display
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func TestUsageBar(t *testing.T) {
+	tests := []struct {
+		percentage float64
+		expected   string
+	}{
+		{0, "░░░░░░░░░░"},
+		{44, "████░░░░░░"},
+		{45, "█████░░░░░"},
+		{100, "██████████"},
+		{130, "██████████"},
+		{-5, "░░░░░░░░░░"},
+	}
+
+	for _, test := range tests {
+		result := UsageBar(test.percentage, 10)
+		if result != test.expected {
+			t.Errorf("UsageBar(%v, 10) = %s; want %s", test.percentage, result, test.expected)
+		}
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	if enabled, err := ColorEnabled("always"); err != nil || !enabled {
+		t.Errorf("ColorEnabled(always) = %v, %v; want true", enabled, err)
+	}
+	if enabled, err := ColorEnabled("never"); err != nil || enabled {
+		t.Errorf("ColorEnabled(never) = %v, %v; want false", enabled, err)
+	}
+	t.Setenv("NO_COLOR", "1")
+	if enabled, err := ColorEnabled("auto"); err != nil || enabled {
+		t.Errorf("ColorEnabled(auto) with NO_COLOR = %v, %v; want false", enabled, err)
+	}
+	if _, err := ColorEnabled("rainbow"); err == nil {
+		t.Errorf("ColorEnabled(rainbow) expected an error")
+	}
+}
+
+func TestTableColorAlignment(t *testing.T) {
+	usages := []pvc.Usage{
+		{Namespace: "kafka", PVC: "data-0", CapacityBytes: 10 << 30, UsedBytes: 95 << 28, PercentageUsed: 95},
+		{Namespace: "web", PVC: "uploads", CapacityBytes: 1 << 30, UsedBytes: 85 << 23, PercentageUsed: 85},
+		{Namespace: "default", PVC: "cache", CapacityBytes: 1 << 30, PercentageUsed: 5},
+	}
+
+	var plain, colored bytes.Buffer
+	newTable(&plain).Show(usages)
+	table := newTable(&colored)
+	table.SetColor(true)
+	table.Show(usages)
+
+	for _, want := range []string{colorCritical + "95%", colorWarning + "85%", colorOK + "5%"} {
+		if !strings.Contains(colored.String(), want) {
+			t.Errorf("colored table is missing %q:\n%s", want, colored.String())
+		}
+	}
+
+	ansi := regexp.MustCompile("\033\\[[0-9;]*m")
+	if stripped := ansi.ReplaceAllString(colored.String(), ""); stripped != plain.String() {
+		t.Errorf("colored table without escape codes =\n%s\nwant\n%s", stripped, plain.String())
+	}
+
+	// Every line starts its Use% column at the same visible offset
+	lines := strings.Split(strings.TrimSpace(plain.String()), "\n")
+	offset := utf8.RuneCountInString(lines[0][:strings.Index(lines[0], "Use%")])
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		cell := fields[len(fields)-2]
+		if got := utf8.RuneCountInString(line[:strings.Index(line, cell)]); got != offset {
+			t.Errorf("line %q has Use%% at column %d; want %d", line, got, offset)
+		}
+	}
+}
+
+func TestGroupsColor(t *testing.T) {
+	groups := []pvc.Group{{
+		Key: "kafka", CapacityBytes: 20 << 30, UsedBytes: 17 << 30, PercentageUsed: 85,
+		Usages: []pvc.Usage{
+			{Namespace: "kafka", PVC: "data-0", CapacityBytes: 10 << 30, UsedBytes: 95 << 28, PercentageUsed: 95},
+			{Namespace: "kafka", PVC: "data-1", CapacityBytes: 10 << 30, UsedBytes: 75 << 28, PercentageUsed: 75},
+		},
+	}}
+
+	var plain, colored bytes.Buffer
+	newTable(&plain).ShowGroups(groups)
+	table := newTable(&colored)
+	table.SetColor(true)
+	table.ShowGroups(groups)
+
+	// The group line is colored by the group's usage, each PVC line by its own
+	for _, want := range []string{colorWarning + "85%", colorCritical + "95%", colorOK + "75%"} {
+		if !strings.Contains(colored.String(), want) {
+			t.Errorf("colored groups are missing %q:\n%s", want, colored.String())
+		}
+	}
+	if strings.Contains(plain.String(), "\033[") {
+		t.Errorf("groups without color contain escape codes:\n%s", plain.String())
+	}
+
+	ansi := regexp.MustCompile("\033\\[[0-9;]*m")
+	if stripped := ansi.ReplaceAllString(colored.String(), ""); stripped != plain.String() {
+		t.Errorf("colored groups without escape codes =\n%s\nwant\n%s", stripped, plain.String())
+	}
+}
@@ -0,0 +1,133 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// Column is a usage table column
+type Column struct {
+	Header string
+	Value  func(u pvc.Usage) string
+	// Colored columns are colored by the usage state when color is enabled
+	Colored bool
+}
+
+// usageBarWidth is the number of cells of the Usage column bar
+const usageBarWidth = 10
+
+// Optional columns of the usage table, selected with --columns
+const (
+	ColumnWorkload  = "workload"
+	ColumnType      = "type"
+	ColumnUsage     = "usage"
+	ColumnSnapshots = "snapshots"
+)
+
+// OptionalColumns are the names accepted by SelectColumns
+var OptionalColumns = []string{ColumnWorkload, ColumnType, ColumnUsage, ColumnSnapshots}
+
+// DefaultColumns returns the columns of the usage table
+func DefaultColumns() []Column {
+	columns, _ := SelectColumns([]string{ColumnWorkload, ColumnUsage})
+	return columns
+}
+
+// SelectColumns returns the usage table columns with the named optional
+// columns. The Namespace, PVC, Size, Used, Avail and Use% columns are always
+// shown, and the columns keep the table order whatever the order of names.
+func SelectColumns(names []string) ([]Column, error) {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !IsOptionalColumn(name) {
+			return nil, fmt.Errorf("unknown column %q (expected %s)", name, strings.Join(OptionalColumns, ", "))
+		}
+		selected[name] = true
+	}
+
+	columns := []Column{
+		{"Namespace", func(u pvc.Usage) string { return u.Namespace }, false},
+		{"PVC", func(u pvc.Usage) string { return u.PVC }, false},
+	}
+	if selected[ColumnType] {
+		columns = append(columns, TypeColumn())
+	}
+	if selected[ColumnWorkload] {
+		columns = append(columns, Column{"Workload", func(u pvc.Usage) string { return workloadOrDash(u.Workload) }, false})
+	}
+	columns = append(columns,
+		Column{"Size", func(u pvc.Usage) string { return HumanizeBytes(u.CapacityBytes) }, false},
+		Column{"Used", func(u pvc.Usage) string { return HumanizeBytes(u.UsedBytes) }, false},
+		Column{"Avail", func(u pvc.Usage) string { return HumanizeBytes(u.AvailableBytes) }, false},
+		Column{"Use%", func(u pvc.Usage) string { return fmt.Sprintf("%.0f%%", u.PercentageUsed) }, true},
+	)
+	if selected[ColumnUsage] {
+		columns = append(columns, Column{"Usage", func(u pvc.Usage) string { return UsageBar(u.PercentageUsed, usageBarWidth) }, true})
+	}
+	if selected[ColumnSnapshots] {
+		columns = append(columns, SnapshotColumns()...)
+	}
+	return columns, nil
+}
+
+// IsOptionalColumn reports whether name is one of OptionalColumns
+func IsOptionalColumn(name string) bool {
+	for _, c := range OptionalColumns {
+		if name == c {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeColumn shows whether a row is a PVC, a generic ephemeral volume's PVC,
+// an emptyDir or a CSI inline volume
+func TypeColumn() Column {
+	return Column{Header: "Type", Value: func(u pvc.Usage) string { return orDash(u.Type) }}
+}
+
+// trendColumn shows an arrow and sparkline of the recent usage of each PVC
+func trendColumn(h *pvc.History) Column {
+	return Column{Header: "Trend", Value: func(u pvc.Usage) string {
+		samples := h.Get(u.Namespace, u.PVC)
+		// Usage moving by less than 0.1 points over the window counts as flat.
+		// The sparkline spans at least 1 point, so that jitter stays low.
+		return TrendArrow(samples, 0.1) + " " + SparklineSpan(samples, 1)
+	}}
+}
+
+// SnapshotColumns are the optional columns of the snapshots of each PVC
+func SnapshotColumns() []Column {
+	stats := func(u pvc.Usage) k8s.SnapshotStats {
+		if u.Snapshots == nil {
+			return k8s.SnapshotStats{}
+		}
+		return *u.Snapshots
+	}
+	return []Column{
+		{Header: "Snaps", Value: func(u pvc.Usage) string {
+			s := stats(u)
+			if s.Ready < s.Count {
+				return fmt.Sprintf("%d (%d not ready)", s.Count, s.Count-s.Ready)
+			}
+			return strconv.Itoa(s.Count)
+		}},
+		{Header: "SnapSize", Value: func(u pvc.Usage) string {
+			if s := stats(u); s.Count > 0 {
+				return HumanizeBytes(s.RestoreSizeBytes)
+			}
+			return "-"
+		}},
+		{Header: "LastSnap", Value: func(u pvc.Usage) string {
+			if s := stats(u); s.Count > 0 {
+				return HumanizeAge(time.Since(s.LatestAt))
+			}
+			return "-"
+		}},
+	}
+}
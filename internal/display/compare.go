@@ -0,0 +1,79 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/perf"
+)
+
+// PerfRow is one PVC in the perf comparison view
+type PerfRow struct {
+	PVC     string
+	Metrics perf.Metrics
+}
+
+// ShowPerfComparison displays one row per PVC and highlights values that stand out from the group
+func ShowPerfComparison(rows []PerfRow) {
+	red := "\033[31m"
+	blue := "\033[34m"
+	bold := "\033[1m"
+	reset := "\033[0m"
+
+	fmt.Printf("%s%sPVC Performance Comparison - %s%s\n\n", bold, blue, time.Now().Format(time.RFC822), reset)
+
+	columns := []struct {
+		title  string
+		value  func(perf.Metrics) float64
+		format func(perf.Metrics) string
+	}{
+		{"IOPS", func(m perf.Metrics) float64 { return float64(m.IOPS) },
+			func(m perf.Metrics) string { return fmt.Sprintf("%d", m.IOPS) }},
+		{"Throughput", func(m perf.Metrics) float64 { return float64(m.Throughput) },
+			func(m perf.Metrics) string { return HumanizeBytes(m.Throughput) + "/s" }},
+		{"Latency", func(m perf.Metrics) float64 { return float64(m.Latency) },
+			func(m perf.Metrics) string { return fmt.Sprintf("%dms", m.Latency) }},
+		{"Util", func(m perf.Metrics) float64 { return m.DiskUtilPct },
+			func(m perf.Metrics) string { return fmt.Sprintf("%.1f%%", m.DiskUtilPct) }},
+	}
+
+	// Pad before coloring, as escape codes would throw off the widths
+	nameWidth := len("PVC")
+	for _, r := range rows {
+		if len(r.PVC) > nameWidth {
+			nameWidth = len(r.PVC)
+		}
+	}
+	const cellWidth = 14
+
+	header := fmt.Sprintf("%-*s", nameWidth+2, "PVC")
+	for _, c := range columns {
+		header += fmt.Sprintf("%-*s", cellWidth, c.title)
+	}
+	fmt.Printf("%s%s%s\n", bold, strings.TrimRight(header, " "), reset)
+
+	outliers := make([][]bool, len(columns))
+	for i, c := range columns {
+		values := make([]float64, len(rows))
+		for j, r := range rows {
+			values[j] = c.value(r.Metrics)
+		}
+		outliers[i] = perf.Outliers(values)
+	}
+
+	for j, r := range rows {
+		line := fmt.Sprintf("%-*s", nameWidth+2, r.PVC)
+		for i, c := range columns {
+			cell := fmt.Sprintf("%-*s", cellWidth, c.format(r.Metrics))
+			if outliers[i][j] {
+				cell = bold + red + cell + reset
+			}
+			line += cell
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	fmt.Printf("\n%sValues in red deviate from the group median by more than 50%%%s\n", red, reset)
+	fmt.Printf("%s%sPress Ctrl+C to stop monitoring%s\n", bold, blue, reset)
+}
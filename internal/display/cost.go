@@ -0,0 +1,132 @@
+package display
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/joseEnrique/pvcusage/internal/cost"
+)
+
+// ShowCosts displays the monthly cost of each PVC. Costs of unpriced
+// StorageClasses are shown as "-".
+func ShowCosts(costs []cost.Cost, currency string) {
+	t := NewTable()
+	fmt.Fprintf(t.writer, "Namespace\tPVC\tStorageClass\tSize\tUsed\tCost%s\tWasted%s\n", currencySuffix(currency), currencySuffix(currency))
+	for _, c := range costs {
+		storageClass := c.StorageClass
+		if storageClass == "" {
+			storageClass = "-"
+		}
+		used := HumanizeBytes(c.UsedBytes)
+		if !c.Mounted {
+			used = "-"
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Namespace, c.PVC, storageClass,
+			HumanizeBytes(c.CapacityBytes), used, formatCost(c.Cost, c.Unpriced), formatCost(c.WastedCost, c.Unpriced))
+	}
+	t.writer.Flush()
+}
+
+// ShowNamespaceCosts displays the monthly cost of each namespace and the total.
+// Costs that leave out unpriced PVCs are marked with "*".
+func ShowNamespaceCosts(rollup []cost.NamespaceCost, currency string) {
+	t := NewTable()
+	fmt.Fprintf(t.writer, "Namespace\tPVCs\tSize\tUsed\tCost%s\tWasted%s\n", currencySuffix(currency), currencySuffix(currency))
+	var total cost.NamespaceCost
+	for _, n := range rollup {
+		fmt.Fprintf(t.writer, "%s\t%d\t%s\t%s\t%s\t%s\n", n.Namespace, n.PVCs,
+			HumanizeBytes(n.CapacityBytes), HumanizeBytes(n.UsedBytes),
+			formatPartialCost(n.Cost, n.Incomplete()), formatPartialCost(n.WastedCost, n.Incomplete()))
+		total.PVCs += n.PVCs
+		total.CapacityBytes += n.CapacityBytes
+		total.UsedBytes += n.UsedBytes
+		total.Cost += n.Cost
+		total.WastedCost += n.WastedCost
+		total.Unpriced += n.Unpriced
+	}
+	fmt.Fprintf(t.writer, "Total\t%d\t%s\t%s\t%s\t%s\n", total.PVCs,
+		HumanizeBytes(total.CapacityBytes), HumanizeBytes(total.UsedBytes),
+		formatPartialCost(total.Cost, total.Incomplete()), formatPartialCost(total.WastedCost, total.Incomplete()))
+	t.writer.Flush()
+	if total.Incomplete() {
+		fmt.Printf("* excludes %d PVCs whose StorageClass has no price\n", total.Unpriced)
+	}
+}
+
+// PrintCostsCSV writes the cost of each PVC to stdout as CSV
+func PrintCostsCSV(costs []cost.Cost) error {
+	return writeCostsCSV(os.Stdout, costs)
+}
+
+// PrintNamespaceCostsCSV writes the cost of each namespace to stdout as CSV
+func PrintNamespaceCostsCSV(rollup []cost.NamespaceCost) error {
+	return writeNamespaceCostsCSV(os.Stdout, rollup)
+}
+
+// writeCostsCSV writes one CSV record per PVC, with sizes in bytes
+func writeCostsCSV(w io.Writer, costs []cost.Cost) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"namespace", "pvc", "storageClass", "capacityBytes", "usedBytes", "mounted", "cost", "wastedCost"})
+	for _, c := range costs {
+		writer.Write([]string{
+			c.Namespace, c.PVC, c.StorageClass,
+			strconv.FormatInt(c.CapacityBytes, 10), strconv.FormatInt(c.UsedBytes, 10), strconv.FormatBool(c.Mounted),
+			csvCost(c.Cost, c.Unpriced), csvCost(c.WastedCost, c.Unpriced),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeNamespaceCostsCSV writes one CSV record per namespace, with sizes in bytes.
+// The costs of namespaces with unpriced PVCs are left empty.
+func writeNamespaceCostsCSV(w io.Writer, rollup []cost.NamespaceCost) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"namespace", "pvcs", "capacityBytes", "usedBytes", "cost", "wastedCost", "unpricedPVCs"})
+	for _, n := range rollup {
+		// A partial sum would understate the namespace, so costs are left empty
+		writer.Write([]string{
+			n.Namespace, strconv.Itoa(n.PVCs),
+			strconv.FormatInt(n.CapacityBytes, 10), strconv.FormatInt(n.UsedBytes, 10),
+			csvCost(n.Cost, n.Incomplete()), csvCost(n.WastedCost, n.Incomplete()), strconv.Itoa(n.Unpriced),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// currencySuffix returns " (USD)" for column headers, or nothing without a currency
+func currencySuffix(currency string) string {
+	if currency == "" {
+		return ""
+	}
+	return " (" + currency + ")"
+}
+
+// formatCost formats a monthly cost for the table
+func formatCost(value float64, unpriced bool) string {
+	if unpriced {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// formatPartialCost formats a sum of costs for the table, marking it with "*"
+// when it leaves out unpriced PVCs
+func formatPartialCost(value float64, incomplete bool) string {
+	if incomplete {
+		return fmt.Sprintf("%.2f*", value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// csvCost formats a monthly cost for CSV, leaving unpriced costs empty
+func csvCost(value float64, unpriced bool) string {
+	if unpriced {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
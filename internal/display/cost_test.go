@@ -0,0 +1,42 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/cost"
+)
+
+func TestWriteCostsCSV(t *testing.T) {
+	costs := []cost.Cost{
+		{Namespace: "kafka", PVC: "data-0", StorageClass: "gp3", CapacityBytes: 1024, UsedBytes: 512, Mounted: true, Cost: 13, WastedCost: 2.005},
+		{Namespace: "web", PVC: "uploads, old", CapacityBytes: 2048, Unpriced: true},
+	}
+	expected := "namespace,pvc,storageClass,capacityBytes,usedBytes,mounted,cost,wastedCost\n" +
+		"kafka,data-0,gp3,1024,512,true,13.00,2.00\n" +
+		"web,\"uploads, old\",,2048,0,false,,\n"
+
+	var buf bytes.Buffer
+	if err := writeCostsCSV(&buf, costs); err != nil {
+		t.Fatalf("writeCostsCSV() unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("writeCostsCSV() =\n%q\nwant\n%q", buf.String(), expected)
+	}
+}
+
+func TestWriteNamespaceCostsCSV(t *testing.T) {
+	rollup := []cost.NamespaceCost{
+		{Namespace: "kafka", PVCs: 2, CapacityBytes: 3072, UsedBytes: 512, Cost: 21.5, WastedCost: 9.25},
+		{Namespace: "web", PVCs: 3, CapacityBytes: 1024, UsedBytes: 0, Cost: 2, WastedCost: 2, Unpriced: 1},
+	}
+	expected := "namespace,pvcs,capacityBytes,usedBytes,cost,wastedCost,unpricedPVCs\nkafka,2,3072,512,21.50,9.25,0\nweb,3,1024,0,,,1\n"
+
+	var buf bytes.Buffer
+	if err := writeNamespaceCostsCSV(&buf, rollup); err != nil {
+		t.Fatalf("writeNamespaceCostsCSV() unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("writeNamespaceCostsCSV() =\n%q\nwant\n%q", buf.String(), expected)
+	}
+}
@@ -1,6 +1,9 @@
 package display
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // HumanizeBytes converts a byte count into a human-readable IEC string
 func HumanizeBytes(bytes int64) string {
@@ -15,3 +18,17 @@ func HumanizeBytes(bytes int64) string {
 	}
 	return fmt.Sprintf("%.1f%ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// HumanizeAge formats a duration like kubectl's AGE column (e.g. 45s, 12m, 5h, 3d)
+func HumanizeAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
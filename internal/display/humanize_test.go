@@ -2,6 +2,7 @@ package display
 
 import (
 	"testing"
+	"time"
 )
 
 func TestHumanizeBytes(t *testing.T) {
@@ -28,3 +29,23 @@ func TestHumanizeBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestHumanizeAge(t *testing.T) {
+	tests := []struct {
+		input    time.Duration
+		expected string
+	}{
+		{45 * time.Second, "45s"},
+		{12 * time.Minute, "12m"},
+		{5 * time.Hour, "5h"},
+		{47 * time.Hour, "47h"},
+		{72 * time.Hour, "3d"},
+	}
+
+	for _, test := range tests {
+		result := HumanizeAge(test.input)
+		if result != test.expected {
+			t.Errorf("HumanizeAge(%s) = %s; want %s", test.input, result, test.expected)
+		}
+	}
+}
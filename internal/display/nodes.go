@@ -0,0 +1,55 @@
+package display
+
+import (
+	"fmt"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// ShowNodes displays the root filesystem and imagefs usage of each node with
+// its DiskPressure condition, followed by the pods using the most ephemeral
+// storage. A non-empty namespace labels the pod figures as limited to it.
+func ShowNodes(nodes []pvc.NodeUsage, namespace string) {
+	ephemeral := "Ephemeral"
+	title := "Top ephemeral-storage pods:"
+	if namespace != "" {
+		ephemeral = "Ephemeral (" + namespace + ")"
+		title = "Top ephemeral-storage pods in namespace " + namespace + ":"
+	}
+
+	t := NewTable()
+	fmt.Fprintln(t.writer, "Node\tDiskPressure\tFS Size\tFS Used\tFS Use%\tImageFS Size\tImageFS Used\tImageFS Use%\t"+ephemeral)
+	for _, n := range nodes {
+		pressure := "False"
+		if n.DiskPressure {
+			pressure = "True"
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\t%s\n", n.Node, pressure, fsCells(n.Fs), fsCells(n.ImageFs), HumanizeBytes(n.EphemeralBytes))
+	}
+	t.writer.Flush()
+
+	fmt.Println("\n" + title)
+	t = NewTable()
+	fmt.Fprintln(t.writer, "  Node\tNamespace\tPod\tUsed")
+	pods := 0
+	for _, n := range nodes {
+		for _, p := range n.TopPods {
+			fmt.Fprintf(t.writer, "  %s\t%s\t%s\t%s\n", n.Node, p.Namespace, p.Pod, HumanizeBytes(p.UsedBytes))
+			pods++
+		}
+	}
+	if pods == 0 {
+		fmt.Println("  none")
+		return
+	}
+	t.writer.Flush()
+}
+
+// fsCells formats the size, used and Use% cells of a filesystem, or dashes
+// when the kubelet doesn't report it
+func fsCells(fs *pvc.FsUsage) string {
+	if fs == nil {
+		return "-\t-\t-"
+	}
+	return fmt.Sprintf("%s\t%s\t%.0f%%", HumanizeBytes(fs.CapacityBytes), HumanizeBytes(fs.UsedBytes), fs.PercentageUsed)
+}
@@ -0,0 +1,118 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// PrintStructured writes v to stdout as "json" or "yaml"
+func PrintStructured(format string, v interface{}) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case "yaml":
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("error encoding YAML: %v", err)
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// EmptyIfNil returns s, or an empty slice when it is nil, so that JSON and
+// YAML encode an empty list rather than null
+func EmptyIfNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// ShowOrphans displays the PVCs that no running pod mounts
+func ShowOrphans(orphans []k8s.OrphanClaim) {
+	t := NewTable()
+	fmt.Fprintln(t.writer, "Namespace\tPVC\tStatus\tSize\tStorageClass\tAge")
+	now := time.Now()
+	for _, o := range orphans {
+		storageClass := o.StorageClass
+		if storageClass == "" {
+			storageClass = "-"
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			o.Namespace, o.PVC, o.Phase, HumanizeBytes(o.CapacityBytes), storageClass, HumanizeAge(now.Sub(o.CreatedAt)))
+	}
+	t.writer.Flush()
+}
+
+// ShowSnapshots displays VolumeSnapshots with their source PVC, followed by
+// their count and total restore size
+func ShowSnapshots(snapshots []k8s.Snapshot) {
+	t := NewTable()
+	fmt.Fprintln(t.writer, "Namespace\tSnapshot\tPVC\tRestoreSize\tReady\tAge\tContent")
+	now := time.Now()
+	var total int64
+	for _, s := range snapshots {
+		// Retained contents outlive their VolumeSnapshot
+		name := s.Name
+		if name == "" {
+			name = "<deleted>"
+		}
+		ready := "true"
+		if !s.ReadyToUse {
+			ready = "false"
+			if s.Error != "" {
+				ready = "false: " + s.Error
+			}
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Namespace, name, orDash(s.SourcePVC),
+			bytesOrDash(s.RestoreSizeBytes), ready, HumanizeAge(now.Sub(s.CreatedAt)), orDash(s.Content))
+		total += s.RestoreSizeBytes
+	}
+	t.writer.Flush()
+	fmt.Printf("\n%d snapshots, %s total restore size\n", len(snapshots), HumanizeBytes(total))
+}
+
+// ShowCapacities displays the requested, status, PV and filesystem sizes of each PVC
+func ShowCapacities(capacities []pvc.Capacity) {
+	t := NewTable()
+	fmt.Fprintln(t.writer, "Namespace\tPVC\tRequested\tStatus\tPV\tFilesystem\tIssues")
+	for _, c := range capacities {
+		issues := "-"
+		if c.HasIssues() {
+			issues = strings.Join(c.Issues, "; ")
+		}
+		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Namespace, c.PVC,
+			bytesOrDash(c.RequestedBytes), bytesOrDash(c.StatusBytes), bytesOrDash(c.VolumeBytes),
+			bytesOrDash(c.FilesystemBytes), issues)
+	}
+	t.writer.Flush()
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// bytesOrDash humanizes a size, or returns "-" when it is unknown
+func bytesOrDash(bytes int64) string {
+	if bytes == 0 {
+		return "-"
+	}
+	return HumanizeBytes(bytes)
+}
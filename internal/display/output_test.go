@@ -0,0 +1,19 @@
+package display
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEmptyIfNil(t *testing.T) {
+	var none []string
+	data, err := json.Marshal(EmptyIfNil(none))
+	if err != nil || string(data) != "[]" {
+		t.Errorf("EmptyIfNil(nil) encodes as %s (%v), want []", data, err)
+	}
+
+	some := []string{"a"}
+	if got := EmptyIfNil(some); len(got) != 1 || &got[0] != &some[0] {
+		t.Errorf("EmptyIfNil(%v) = %v, want the same slice", some, got)
+	}
+}
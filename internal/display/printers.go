@@ -0,0 +1,180 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// TemplateFormats are the kubectl-style -o formats that take an argument after "="
+var TemplateFormats = []string{"custom-columns", "go-template", "go-template-file", "jsonpath", "jsonpath-file"}
+
+// IsTemplateOutput reports whether output is one of TemplateFormats
+func IsTemplateOutput(output string) bool {
+	format, _, ok := strings.Cut(output, "=")
+	if !ok {
+		return false
+	}
+	for _, f := range TemplateFormats {
+		if format == f {
+			return true
+		}
+	}
+	return false
+}
+
+// UsagePrinter prints usages with a custom-columns, go-template or jsonpath output format
+type UsagePrinter struct {
+	columns  []Column
+	template *template.Template
+	jsonPath *jsonpath.JSONPath
+}
+
+// NewUsagePrinter parses an output format such as "custom-columns=NS:.namespace,PVC:.pvc"
+// or "jsonpath={.items[*].pvc}". Files are read for the -file variants.
+func NewUsagePrinter(output string) (*UsagePrinter, error) {
+	format, arg, _ := strings.Cut(output, "=")
+	if arg == "" {
+		return nil, fmt.Errorf("-o %s requires a value (e.g. %s=...)", format, format)
+	}
+
+	if strings.HasSuffix(format, "-file") {
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", format, err)
+		}
+		arg = string(data)
+		format = strings.TrimSuffix(format, "-file")
+	}
+
+	switch format {
+	case "custom-columns":
+		columns, err := ParseCustomColumns(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &UsagePrinter{columns: columns}, nil
+	case "go-template":
+		tmpl, err := template.New("output").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing go-template: %v", err)
+		}
+		return &UsagePrinter{template: tmpl}, nil
+	case "jsonpath":
+		jp := jsonpath.New("output")
+		if err := jp.Parse(arg); err != nil {
+			return nil, fmt.Errorf("error parsing jsonpath %s: %v", arg, err)
+		}
+		return &UsagePrinter{jsonPath: jp}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// Print writes the usages to stdout
+func (p *UsagePrinter) Print(usages []pvc.Usage) error {
+	return p.print(os.Stdout, usages)
+}
+
+// print writes the usages to w. Templates see a list like kubectl's, with the
+// usage records under .items.
+func (p *UsagePrinter) print(w io.Writer, usages []pvc.Usage) error {
+	if p.columns != nil {
+		table := newTable(w)
+		table.SetColumns(p.columns)
+		table.Show(usages)
+		return nil
+	}
+
+	items := make([]interface{}, len(usages))
+	for i, u := range usages {
+		items[i] = usageRecord(u)
+	}
+	list := map[string]interface{}{"items": items}
+
+	if p.template != nil {
+		if err := p.template.Execute(w, list); err != nil {
+			return fmt.Errorf("error executing go-template: %v", err)
+		}
+		return nil
+	}
+	if err := p.jsonPath.Execute(w, list); err != nil {
+		return fmt.Errorf("error executing jsonpath: %v", err)
+	}
+	return nil
+}
+
+// ParseCustomColumns parses a kubectl custom-columns spec: HEADER:.path pairs
+// separated by commas. Paths are relative to a usage record.
+func ParseCustomColumns(spec string) ([]Column, error) {
+	var columns []Column
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("invalid custom-columns entry %q (expected HEADER:.path)", part)
+		}
+
+		jp := jsonpath.New(header).AllowMissingKeys(true)
+		if err := jp.Parse(relaxedJSONPath(path)); err != nil {
+			return nil, fmt.Errorf("invalid custom-columns path %q: %v", path, err)
+		}
+		columns = append(columns, Column{Header: header, Value: jsonPathValue(jp)})
+	}
+	return columns, nil
+}
+
+// relaxedJSONPath turns ".pvc" or "pvc" into "{.pvc}" like kubectl does
+func relaxedJSONPath(path string) string {
+	if strings.HasPrefix(path, "{") {
+		return path
+	}
+	return "{." + strings.TrimPrefix(path, ".") + "}"
+}
+
+// jsonPathValue evaluates a path on the usage record, printing <none> when it
+// matches nothing
+func jsonPathValue(jp *jsonpath.JSONPath) func(u pvc.Usage) string {
+	return func(u pvc.Usage) string {
+		results, err := jp.FindResults(usageRecord(u))
+		if err != nil || len(results) == 0 || len(results[0]) == 0 {
+			return "<none>"
+		}
+
+		var values []string
+		for _, result := range results[0] {
+			var buf bytes.Buffer
+			if err := jp.PrintResults(&buf, []reflect.Value{result}); err != nil {
+				return "<none>"
+			}
+			values = append(values, buf.String())
+		}
+		return strings.Join(values, ",")
+	}
+}
+
+// usageRecord is the object printers see for a usage: its JSON fields plus
+// the human-readable size, used, available and use values of the table
+func usageRecord(u pvc.Usage) map[string]interface{} {
+	// Usage only holds strings and numbers, so the round trip can't fail.
+	// Numbers stay json.Number so byte counts aren't printed as floats.
+	record := make(map[string]interface{})
+	data, _ := json.Marshal(u)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.Decode(&record)
+
+	record["size"] = HumanizeBytes(u.CapacityBytes)
+	record["used"] = HumanizeBytes(u.UsedBytes)
+	record["available"] = HumanizeBytes(u.AvailableBytes)
+	record["use"] = fmt.Sprintf("%.0f%%", u.PercentageUsed)
+	return record
+}
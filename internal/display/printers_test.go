@@ -0,0 +1,87 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func TestUsagePrinter(t *testing.T) {
+	usages := []pvc.Usage{
+		{Namespace: "kafka", PVC: "data-0", Workload: "StatefulSet/kafka", CapacityBytes: 10 << 30, UsedBytes: 8 << 30, AvailableBytes: 2 << 30, PercentageUsed: 80},
+		{Namespace: "web", PVC: "uploads", CapacityBytes: 1 << 30, UsedBytes: 0, AvailableBytes: 1 << 30},
+	}
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{
+			"custom-columns=NS:.namespace,PVC:pvc,FREE:.available,OWNER:.workload",
+			"NS     PVC      FREE    OWNER\nkafka  data-0   2.0GiB  StatefulSet/kafka\nweb    uploads  1.0GiB  <none>\n",
+		},
+		{
+			"custom-columns=PVC:.pvc,BYTES:.capacityBytes",
+			"PVC      BYTES\ndata-0   10737418240\nuploads  1073741824\n",
+		},
+		{
+			"jsonpath={.items[*].pvc}",
+			"data-0 uploads",
+		},
+		{
+			`jsonpath={range .items[*]}{.namespace}/{.pvc} {.use}{"\n"}{end}`,
+			"kafka/data-0 80%\nweb/uploads 0%\n",
+		},
+		{
+			`go-template={{range .items}}{{.pvc}}={{.used}}{{"\n"}}{{end}}`,
+			"data-0=8.0GiB\nuploads=0B\n",
+		},
+	}
+
+	for _, tt := range tests {
+		printer, err := NewUsagePrinter(tt.output)
+		if err != nil {
+			t.Errorf("NewUsagePrinter(%q) unexpected error: %v", tt.output, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := printer.print(&buf, usages); err != nil {
+			t.Errorf("print(%q) unexpected error: %v", tt.output, err)
+			continue
+		}
+		if buf.String() != tt.want {
+			t.Errorf("print(%q) =\n%q\nwant\n%q", tt.output, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestNewUsagePrinterErrors(t *testing.T) {
+	for _, output := range []string{
+		"custom-columns=",
+		"custom-columns=NS",
+		"custom-columns=NS:.namespace,:.pvc",
+		"go-template={{.items",
+		"jsonpath={.items[",
+		"jsonpath-file=/does/not/exist",
+		"yaml=x",
+	} {
+		if _, err := NewUsagePrinter(output); err == nil {
+			t.Errorf("NewUsagePrinter(%q) expected error", output)
+		}
+	}
+}
+
+func TestIsTemplateOutput(t *testing.T) {
+	for output, want := range map[string]bool{
+		"custom-columns=NS:.namespace": true,
+		"jsonpath-file=report.txt":     true,
+		"go-template":                  false,
+		"json":                         false,
+		"table=x":                      false,
+	} {
+		if got := IsTemplateOutput(output); got != want {
+			t.Errorf("IsTemplateOutput(%q) = %v, want %v", output, got, want)
+		}
+	}
+}
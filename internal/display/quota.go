@@ -0,0 +1,75 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// ShowQuotas displays the storage quotas of each namespace next to its PVC
+// requests and used space, followed by the expansions they would block
+func ShowQuotas(namespaces []pvc.NamespaceQuota, expandBy float64) {
+	t := NewTable()
+	fmt.Fprintln(t.writer, "Namespace\tPVCs\tRequests\tData\tLimit\tHard\tUsed\tLeft")
+	for _, n := range namespaces {
+		prefix := fmt.Sprintf("%s\t%d\t%s\t%s", n.Namespace, n.PVCs, HumanizeBytes(n.RequestedBytes), HumanizeBytes(n.UsedBytes))
+		if len(n.Quotas) == 0 && n.MaxPVCBytes == 0 {
+			fmt.Fprintf(t.writer, "%s\t-\t\t\t\n", prefix)
+			continue
+		}
+		for _, q := range n.Quotas {
+			fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\t%s\n", prefix, quotaName(q),
+				quotaValue(q, q.Hard), quotaValue(q, q.Used), quotaValue(q, max(q.Hard-q.Used, 0)))
+			// Namespace totals are only shown on the first line
+			prefix = "\t\t\t"
+		}
+		if n.MaxPVCBytes > 0 {
+			fmt.Fprintf(t.writer, "%s\tLimitRange max per PVC\t%s\t\t\n", prefix, HumanizeBytes(n.MaxPVCBytes))
+		}
+	}
+	t.writer.Flush()
+
+	fmt.Printf("\nBlocked expansions (PVCs above the threshold grown by %.0f%%):\n", expandBy)
+	blocked := 0
+	for _, n := range namespaces {
+		for _, b := range n.Blocked {
+			fmt.Printf("  %s/%s\n", n.Namespace, blockerMessage(b))
+			blocked++
+		}
+	}
+	if blocked == 0 {
+		fmt.Println("  none")
+	}
+}
+
+// quotaName names a quota resource, e.g. "storage: gold requests.storage"
+func quotaName(q k8s.StorageQuota) string {
+	if q.StorageClass != "" {
+		return q.Quota + ": " + q.StorageClass + " " + q.Resource
+	}
+	return q.Quota + ": " + q.Resource
+}
+
+// quotaValue formats bytes for storage quotas and counts for PVC quotas
+func quotaValue(q k8s.StorageQuota, value int64) string {
+	if q.Resource == "persistentvolumeclaims" {
+		return strconv.FormatInt(value, 10)
+	}
+	return HumanizeBytes(value)
+}
+
+// blockerMessage describes why the expansion of a PVC would be rejected
+func blockerMessage(b pvc.Blocker) string {
+	if b.Limit == "LimitRange" {
+		return fmt.Sprintf("%s (%.0f%% used): growing to %s exceeds the LimitRange maximum of %s",
+			b.PVC, b.PercentageUsed, HumanizeBytes(b.TargetBytes), HumanizeBytes(b.AllowedBytes))
+	}
+	quota := b.Limit
+	if b.StorageClass != "" {
+		quota += " (" + b.StorageClass + ")"
+	}
+	return fmt.Sprintf("%s (%.0f%% used): growing by %s exceeds the %s left in quota %s",
+		b.PVC, b.PercentageUsed, HumanizeBytes(b.GrowthBytes), HumanizeBytes(b.AllowedBytes), quota)
+}
@@ -0,0 +1,33 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func TestBlockerMessage(t *testing.T) {
+	tests := []struct {
+		blocker  pvc.Blocker
+		expected string
+	}{
+		{
+			pvc.Blocker{PVC: "data-0", PercentageUsed: 91.6, GrowthBytes: 5 << 30, TargetBytes: 15 << 30, Limit: "LimitRange", AllowedBytes: 10 << 30},
+			"data-0 (92% used): growing to 15.0GiB exceeds the LimitRange maximum of 10.0GiB",
+		},
+		{
+			pvc.Blocker{PVC: "data-0", PercentageUsed: 85, GrowthBytes: 5 << 30, Limit: "storage", AllowedBytes: 1 << 30},
+			"data-0 (85% used): growing by 5.0GiB exceeds the 1.0GiB left in quota storage",
+		},
+		{
+			pvc.Blocker{PVC: "wal", PercentageUsed: 80, GrowthBytes: 1 << 30, Limit: "storage", StorageClass: "gold"},
+			"wal (80% used): growing by 1.0GiB exceeds the 0B left in quota storage (gold)",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := blockerMessage(tt.blocker); got != tt.expected {
+			t.Errorf("blockerMessage(%+v) = %q, want %q", tt.blocker, got, tt.expected)
+		}
+	}
+}
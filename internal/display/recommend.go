@@ -0,0 +1,96 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// ShowRecommendations displays the PVCs to grow, which can be expanded in
+// place, apart from the PVCs to shrink, which must be migrated to a new PVC
+func ShowRecommendations(recommendations []pvc.Recommendation) {
+	var grow, shrink []pvc.Recommendation
+	keep := 0
+	for _, r := range recommendations {
+		switch r.Action {
+		case pvc.ActionGrow:
+			grow = append(grow, r)
+		case pvc.ActionShrink:
+			shrink = append(shrink, r)
+		default:
+			keep++
+		}
+	}
+
+	fmt.Println("Grow (expand in place):")
+	showRecommendationTable(grow, true)
+	fmt.Println()
+	fmt.Println("Shrink (migrate the data to a new, smaller PVC):")
+	showRecommendationTable(shrink, false)
+	fmt.Printf("\n%d PVCs are already sized to the policy\n", keep)
+}
+
+// showRecommendationTable displays one section of the recommendations
+func showRecommendationTable(recommendations []pvc.Recommendation, growing bool) {
+	if len(recommendations) == 0 {
+		fmt.Println("  none")
+		return
+	}
+
+	t := NewTable()
+	fmt.Fprintln(t.writer, "  Namespace\tPVC\tCurrent\tUsed\tSamples\tTarget\tNote")
+	for _, r := range recommendations {
+		note := "-"
+		if growing && !r.Expandable {
+			note = "StorageClass does not allow expansion, migrate instead"
+		}
+		fmt.Fprintf(t.writer, "  %s\t%s\t%s\t%s\t%d\t%s\t%s\n", r.Namespace, r.PVC, HumanizeBytes(r.CurrentBytes),
+			HumanizeBytes(r.UsedBytes), r.Samples, HumanizeBytes(r.TargetBytes), note)
+	}
+	t.writer.Flush()
+}
+
+// PrintExpansionPatches writes the expansions as PVC manifests for kubectl apply
+func PrintExpansionPatches(recommendations []pvc.Recommendation) error {
+	return writeExpansionPatches(os.Stdout, recommendations)
+}
+
+// writeExpansionPatches writes one YAML document per PVC that can grow in place.
+// Each manifest only sets the storage request, leaving the other fields alone.
+func writeExpansionPatches(w io.Writer, recommendations []pvc.Recommendation) error {
+	first := true
+	for _, r := range recommendations {
+		if r.Action != pvc.ActionGrow || !r.Expandable {
+			continue
+		}
+		patch := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolumeClaim",
+			"metadata":   map[string]string{"name": r.PVC, "namespace": r.Namespace},
+			"spec": map[string]interface{}{
+				"resources": map[string]interface{}{
+					"requests": map[string]string{
+						"storage": resource.NewQuantity(r.TargetBytes, resource.BinarySI).String(),
+					},
+				},
+			},
+		}
+		data, err := yaml.Marshal(patch)
+		if err != nil {
+			return fmt.Errorf("error encoding patch: %v", err)
+		}
+		if !first {
+			fmt.Fprintln(w, "---")
+		}
+		first = false
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
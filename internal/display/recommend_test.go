@@ -0,0 +1,45 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func TestWriteExpansionPatches(t *testing.T) {
+	recommendations := []pvc.Recommendation{
+		{Namespace: "kafka", PVC: "data-0", TargetBytes: 20 << 30, Action: pvc.ActionGrow, Expandable: true},
+		{Namespace: "kafka", PVC: "legacy", TargetBytes: 20 << 30, Action: pvc.ActionGrow},
+		{Namespace: "web", PVC: "uploads", TargetBytes: 10 << 30, Action: pvc.ActionShrink, Expandable: true},
+		{Namespace: "db", PVC: "wal", TargetBytes: 1536 << 20, Action: pvc.ActionGrow, Expandable: true},
+	}
+	expected := `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-0
+  namespace: kafka
+spec:
+  resources:
+    requests:
+      storage: 20Gi
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: wal
+  namespace: db
+spec:
+  resources:
+    requests:
+      storage: 1536Mi
+`
+
+	var buf bytes.Buffer
+	if err := writeExpansionPatches(&buf, recommendations); err != nil {
+		t.Fatalf("writeExpansionPatches() unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("writeExpansionPatches() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
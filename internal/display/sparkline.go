@@ -0,0 +1,80 @@
+package display
+
+// sparkBlocks are the unicode bars used by sparklines, from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders non-negative values as a unicode bar graph scaled from 0
+// to their max, so that small jitter around a steady value stays flat
+func Sparkline(values []float64) string {
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	return SparklineRange(values, 0, max)
+}
+
+// SparklineSpan renders values as a unicode bar graph scaled between their
+// min and max, over at least minSpan. Moves smaller than minSpan stay low, so
+// jitter looks flat while steady growth climbs.
+func SparklineSpan(values []float64, minSpan float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	if max-min < minSpan {
+		max = min + minSpan
+	}
+	return SparklineRange(values, min, max)
+}
+
+// SparklineRange renders values as a unicode bar graph on a fixed scale from
+// low to high, e.g. 0 to 100 for percentages. Values outside are clamped.
+func SparklineRange(values []float64, low, high float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	top := len(sparkBlocks) - 1
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if high > low {
+			level = int((v - low) / (high - low) * float64(top))
+		}
+		if level < 0 {
+			level = 0
+		}
+		if level > top {
+			level = top
+		}
+		line[i] = sparkBlocks[level]
+	}
+	return string(line)
+}
+
+// TrendArrow returns ↑, ↓ or → depending on whether the last value moved away
+// from the first by more than tolerance
+func TrendArrow(values []float64, tolerance float64) string {
+	if len(values) < 2 {
+		return "→"
+	}
+	delta := values[len(values)-1] - values[0]
+	switch {
+	case delta > tolerance:
+		return "↑"
+	case delta < -tolerance:
+		return "↓"
+	default:
+		return "→"
+	}
+}
@@ -0,0 +1,93 @@
+package display
+
+import (
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		input    []float64
+		expected string
+	}{
+		{nil, ""},
+		{[]float64{0, 0}, "▁▁"},
+		{[]float64{5}, "█"},
+		{[]float64{3, 3, 3}, "███"},
+		{[]float64{0, 50, 100}, "▁▄█"},
+		{[]float64{10, 20, 30, 40, 50, 60, 70, 80}, "▁▂▃▄▅▆▇█"},
+		// Jitter around a steady value isn't magnified to full height
+		{[]float64{1000, 1010, 995, 1005}, "▇█▇▇"},
+	}
+
+	for _, test := range tests {
+		result := Sparkline(test.input)
+		if result != test.expected {
+			t.Errorf("Sparkline(%v) = %s; want %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestSparklineRange(t *testing.T) {
+	tests := []struct {
+		input    []float64
+		expected string
+	}{
+		{nil, ""},
+		{[]float64{0, 50, 100}, "▁▄█"},
+		{[]float64{-5, 120}, "▁█"},
+	}
+
+	for _, test := range tests {
+		result := SparklineRange(test.input, 0, 100)
+		if result != test.expected {
+			t.Errorf("SparklineRange(%v, 0, 100) = %s; want %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestSparklineSpan(t *testing.T) {
+	// A PVC filling at 1 point per minute, sampled every 5 seconds
+	slowFill := make([]float64, 20)
+	for i := range slowFill {
+		slowFill[i] = 50 + float64(i)/12
+	}
+
+	tests := []struct {
+		input    []float64
+		expected string
+	}{
+		{nil, ""},
+		{[]float64{50, 50, 50}, "▁▁▁"},
+		// Jitter within the minimum span stays low
+		{[]float64{50, 50.2, 49.9, 50.1}, "▁▃▁▂"},
+		{slowFill, "▁▁▁▂▂▂▃▃▃▄▄▅▅▅▆▆▆▇▇█"},
+		{[]float64{40, 60, 80}, "▁▄█"},
+	}
+
+	for _, test := range tests {
+		result := SparklineSpan(test.input, 1)
+		if result != test.expected {
+			t.Errorf("SparklineSpan(%v, 1) = %s; want %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestTrendArrow(t *testing.T) {
+	tests := []struct {
+		input    []float64
+		expected string
+	}{
+		{nil, "→"},
+		{[]float64{50}, "→"},
+		{[]float64{50, 51, 52}, "↑"},
+		{[]float64{50, 49.95}, "→"},
+		{[]float64{50, 40}, "↓"},
+	}
+
+	for _, test := range tests {
+		result := TrendArrow(test.input, 0.1)
+		if result != test.expected {
+			t.Errorf("TrendArrow(%v) = %s; want %s", test.input, result, test.expected)
+		}
+	}
+}
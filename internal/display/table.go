@@ -2,11 +2,13 @@ package display
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
 	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 
 	"github.com/joseEnrique/pvcusage/internal/k8s"
 	"github.com/joseEnrique/pvcusage/internal/perf"
@@ -15,27 +17,143 @@ import (
 
 // Table displays PVC usage information in a formatted table
 type Table struct {
-	writer *tabwriter.Writer
+	out        io.Writer
+	writer     *tabwriter.Writer
+	columns    []Column
+	history    *pvc.History
+	thresholds pvc.Thresholds
+	color      bool
 }
 
-// NewTable creates a new table display
+// NewTable creates a new table display with the default columns
 func NewTable() *Table {
+	return newTable(os.Stdout)
+}
+
+// newTable creates a table display that writes to w
+func newTable(w io.Writer) *Table {
 	return &Table{
-		writer: tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0),
+		out:        w,
+		writer:     tabwriter.NewWriter(w, 0, 0, 2, ' ', 0),
+		columns:    DefaultColumns(),
+		thresholds: pvc.Thresholds{Warn: 80, Crit: 90},
 	}
 }
 
+// SetColumns replaces the columns of the table
+func (t *Table) SetColumns(columns []Column) {
+	t.columns = columns
+}
+
+// SetHistory adds a Trend column showing the recent usage of each PVC
+func (t *Table) SetHistory(h *pvc.History) {
+	t.history = h
+}
+
+// SetThresholds sets the usage percentages at which cells turn yellow and red
+func (t *Table) SetThresholds(thresholds pvc.Thresholds) {
+	t.thresholds = thresholds
+}
+
+// SetColor enables coloring the usage cells by threshold state
+func (t *Table) SetColor(color bool) {
+	t.color = color
+}
+
 // Show displays the PVC usages in a formatted table
 func (t *Table) Show(usages []pvc.Usage) {
-	fmt.Fprintln(t.writer, "Namespace\tPVC\tSize\tUsed\tAvail\tUse%")
+	columns := t.columns
+	if t.history != nil {
+		columns = append(columns[:len(columns):len(columns)], trendColumn(t.history))
+	}
+
+	header := make([]string, len(columns))
+	colored := make([]bool, len(columns))
+	for i, c := range columns {
+		header[i] = c.Header
+		colored[i] = c.Colored
+	}
+	rows := [][]string{header}
+	percentages := []float64{-1}
 	for _, u := range usages {
-		capStr := HumanizeBytes(u.CapacityBytes)
-		usedStr := HumanizeBytes(u.UsedBytes)
-		availStr := HumanizeBytes(u.AvailableBytes)
-		fmt.Fprintf(t.writer, "%s\t%s\t%s\t%s\t%s\t%.0f%%\n",
-			u.Namespace, u.PVC, capStr, usedStr, availStr, u.PercentageUsed)
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = c.Value(u)
+		}
+		rows = append(rows, row)
+		percentages = append(percentages, u.PercentageUsed)
+	}
+	t.writeRows(rows, colored, percentages)
+}
+
+// ShowGroups displays grouped PVC usages, one summary line per group followed
+// by its PVCs. Use% is colored by the usage of the group or PVC of its line.
+func (t *Table) ShowGroups(groups []pvc.Group) {
+	rows := [][]string{{"Group", "PVCs", "Size", "Used", "Avail", "Use%"}}
+	percentages := []float64{-1}
+	for _, g := range groups {
+		rows = append(rows, []string{g.Key, fmt.Sprintf("%d", len(g.Usages)), HumanizeBytes(g.CapacityBytes),
+			HumanizeBytes(g.UsedBytes), HumanizeBytes(g.AvailableBytes), fmt.Sprintf("%.0f%%", g.PercentageUsed)})
+		percentages = append(percentages, g.PercentageUsed)
+		for _, u := range g.Usages {
+			rows = append(rows, []string{"  " + u.Namespace + "/" + u.PVC, "", HumanizeBytes(u.CapacityBytes),
+				HumanizeBytes(u.UsedBytes), HumanizeBytes(u.AvailableBytes), fmt.Sprintf("%.0f%%", u.PercentageUsed)})
+			percentages = append(percentages, u.PercentageUsed)
+		}
+	}
+	t.writeRows(rows, []bool{false, false, false, false, false, true}, percentages)
+}
+
+// writeRows writes rows with their cells padded to the visible width of each
+// column. With color enabled, the cells of the colored columns of row r get
+// the threshold color of percentages[r]; a negative percentage, as for the
+// header, leaves the row uncolored. Cells are padded before being colored, as
+// tabwriter would count escape codes as text.
+func (t *Table) writeRows(rows [][]string, colored []bool, percentages []float64) {
+	widths := make([]int, len(colored))
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	for r, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			padding := ""
+			if i < len(row)-1 {
+				padding = strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2)
+			}
+			if t.color && colored[i] && percentages[r] >= 0 && cell != "" {
+				cell = stateColor(t.thresholds.State(percentages[r])) + cell + colorReset
+			}
+			line.WriteString(cell + padding)
+		}
+		fmt.Fprintln(t.out, strings.TrimRight(line.String(), " "))
+	}
+}
+
+// ShowTotals displays the totals of the listed PVCs below the table
+func (t *Table) ShowTotals(totals pvc.Totals) {
+	fmt.Fprintln(t.out)
+	fmt.Fprintf(t.out, "Total: %d PVCs, %s size, %s used, %s available (%.0f%% used)\n",
+		totals.PVCs, HumanizeBytes(totals.CapacityBytes), HumanizeBytes(totals.UsedBytes),
+		HumanizeBytes(totals.AvailableBytes), totals.PercentageUsed)
+	fmt.Fprintf(t.out, "Above warn (%v%%): %d, above crit (%v%%): %d\n",
+		totals.Warn, totals.AboveWarn, totals.Crit, totals.AboveCrit)
+	if totals.FullestNamespace != "" {
+		fmt.Fprintf(t.out, "Fullest namespace: %s (%.0f%% used)\n", totals.FullestNamespace, totals.FullestNamespacePercentageUsed)
+	}
+}
+
+// workloadOrDash returns the workload name, or "-" when the PVC has no known consumer
+func workloadOrDash(workload string) string {
+	if workload == "" {
+		return "-"
 	}
-	t.writer.Flush()
+	return workload
 }
 
 // ClearScreen clears the terminal (works on most ANSI terminals)
@@ -44,8 +162,8 @@ func ClearScreen() {
 }
 
 // UpdateTable queries all nodes, extracts PVC usage data, and prints a formatted table.
-func UpdateTable(client *k8s.Client, filter string, topN int) {
-	usages, err := pvc.GetUsages(client)
+func UpdateTable(client *k8s.Client, opts pvc.Options, filter string, topN int) {
+	usages, err := pvc.GetUsages(client, opts)
 	if err != nil {
 		log.Printf("Error getting PVC usages: %v", err)
 		return
@@ -63,8 +181,8 @@ func UpdateTable(client *k8s.Client, filter string, topN int) {
 	table.Show(limitedUsages)
 }
 
-// ShowPerfMetrics displays performance metrics for a PVC
-func ShowPerfMetrics(metrics perf.Metrics) {
+// ShowPerfMetrics displays performance metrics for a PVC, with graphs of the recent history
+func ShowPerfMetrics(metrics perf.Metrics, history []perf.Metrics) {
 	// ANSI colors for better visualization
 	green := "\033[32m"
 	yellow := "\033[33m"
@@ -109,8 +227,16 @@ func ShowPerfMetrics(metrics perf.Metrics) {
 
 	// Performance metrics
 	fmt.Printf("\n%s%sPerformance Metrics:%s\n", bold, cyan, reset)
-	fmt.Printf("  IOPS:          %d ops/sec\n", metrics.IOPS)
-	fmt.Printf("  Throughput:    %s/sec\n", HumanizeBytes(metrics.Throughput))
+	iopsHistory := make([]float64, len(history))
+	throughputHistory := make([]float64, len(history))
+	for i, h := range history {
+		iopsHistory[i] = float64(h.IOPS)
+		throughputHistory[i] = float64(h.Throughput)
+	}
+	fmt.Printf("  IOPS:          %-14s %s%s %s%s\n", fmt.Sprintf("%d ops/sec", metrics.IOPS),
+		cyan, TrendArrow(iopsHistory, 0), Sparkline(iopsHistory), reset)
+	fmt.Printf("  Throughput:    %-14s %s%s %s%s\n", HumanizeBytes(metrics.Throughput)+"/sec",
+		cyan, TrendArrow(throughputHistory, 0), Sparkline(throughputHistory), reset)
 	fmt.Printf("  Latency:       %dms\n", metrics.Latency)
 	fmt.Printf("  Disk Util:     %.1f%%\n", metrics.DiskUtilPct)
 
@@ -211,3 +337,37 @@ func highlightKeywords(line string) string {
 
 	return line
 }
+
+// ShowPerfSummary displays min/avg/max/p95 statistics of a recorded perf session
+func ShowPerfSummary(samples []perf.Metrics, summaries []perf.MetricSummary) {
+	if len(samples) > 0 {
+		first, last := samples[0].Timestamp, samples[len(samples)-1].Timestamp
+		fmt.Printf("%d samples from %s to %s (%s)\n\n", len(samples),
+			first.Format(time.RFC822), last.Format(time.RFC822), last.Sub(first).Round(time.Second))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Metric\tMin\tAvg\tMax\tp95")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name,
+			formatMetric(s.Min, s.Unit), formatMetric(s.Avg, s.Unit),
+			formatMetric(s.Max, s.Unit), formatMetric(s.P95, s.Unit))
+	}
+	w.Flush()
+}
+
+// formatMetric formats a metric value according to its unit
+func formatMetric(value float64, unit string) string {
+	switch unit {
+	case "bytes":
+		return HumanizeBytes(int64(value))
+	case "bytes/s":
+		return HumanizeBytes(int64(value)) + "/s"
+	case "ms":
+		return fmt.Sprintf("%.1fms", value)
+	case "%":
+		return fmt.Sprintf("%.1f%%", value)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
@@ -0,0 +1,99 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func TestShowTotals(t *testing.T) {
+	tests := []struct {
+		totals   pvc.Totals
+		expected string
+	}{
+		{
+			pvc.Totals{
+				PVCs: 3, CapacityBytes: 30 << 30, UsedBytes: 20 << 30, AvailableBytes: 10 << 30, PercentageUsed: 66.7,
+				Warn: 80, Crit: 92.5, AboveWarn: 2, AboveCrit: 1, FullestNamespace: "kafka", FullestNamespacePercentageUsed: 91,
+			},
+			"\nTotal: 3 PVCs, 30.0GiB size, 20.0GiB used, 10.0GiB available (67% used)\n" +
+				"Above warn (80%): 2, above crit (92.5%): 1\n" +
+				"Fullest namespace: kafka (91% used)\n",
+		},
+		{
+			pvc.Totals{Warn: 80, Crit: 90},
+			"\nTotal: 0 PVCs, 0B size, 0B used, 0B available (0% used)\n" +
+				"Above warn (80%): 0, above crit (90%): 0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		newTable(&buf).ShowTotals(tt.totals)
+		if buf.String() != tt.expected {
+			t.Errorf("ShowTotals(%+v) =\n%q\nwant\n%q", tt.totals, buf.String(), tt.expected)
+		}
+	}
+}
+
+func TestSnapshotColumns(t *testing.T) {
+	usages := []pvc.Usage{
+		{PVC: "data", Snapshots: &k8s.SnapshotStats{Count: 3, Ready: 2, RestoreSizeBytes: 15 << 30, LatestAt: time.Now().Add(-2 * time.Hour)}},
+		{PVC: "cache", Snapshots: &k8s.SnapshotStats{}},
+		{PVC: "uncollected"},
+	}
+	expected := [][]string{
+		{"3 (1 not ready)", "15.0GiB", "2h"},
+		{"0", "-", "-"},
+		{"0", "-", "-"},
+	}
+
+	columns := SnapshotColumns()
+	for i, u := range usages {
+		for j, c := range columns {
+			if got := c.Value(u); got != expected[i][j] {
+				t.Errorf("%s column of %s = %q, want %q", c.Header, u.PVC, got, expected[i][j])
+			}
+		}
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	headers := func(columns []Column) string {
+		var names []string
+		for _, c := range columns {
+			names = append(names, c.Header)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		names   []string
+		want    string
+		wantErr bool
+	}{
+		{nil, "Namespace,PVC,Size,Used,Avail,Use%", false},
+		{[]string{ColumnUsage, ColumnWorkload}, "Namespace,PVC,Workload,Size,Used,Avail,Use%,Usage", false},
+		{[]string{ColumnType}, "Namespace,PVC,Type,Size,Used,Avail,Use%", false},
+		{[]string{ColumnSnapshots}, "Namespace,PVC,Size,Used,Avail,Use%,Snaps,SnapSize,LastSnap", false},
+		{[]string{"node"}, "", true},
+	}
+
+	for _, tt := range tests {
+		columns, err := SelectColumns(tt.names)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SelectColumns(%v) error = %v, wantErr %v", tt.names, err, tt.wantErr)
+			continue
+		}
+		if got := headers(columns); !tt.wantErr && got != tt.want {
+			t.Errorf("SelectColumns(%v) = %s, want %s", tt.names, got, tt.want)
+		}
+	}
+	if got := headers(DefaultColumns()); got != "Namespace,PVC,Workload,Size,Used,Avail,Use%,Usage" {
+		t.Errorf("DefaultColumns() = %s", got)
+	}
+}
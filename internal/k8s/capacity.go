@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClaimSize is the size of a PVC as recorded by the claim and its volume
+type ClaimSize struct {
+	Namespace      string `json:"namespace"`
+	PVC            string `json:"pvc"`
+	Volume         string `json:"volume,omitempty"`
+	StorageClass   string `json:"storageClass,omitempty"`
+	RequestedBytes int64  `json:"requestedBytes"` // spec.resources.requests
+	StatusBytes    int64  `json:"statusBytes"`    // status.capacity
+	VolumeBytes    int64  `json:"volumeBytes"`    // capacity of the bound PV
+	// Resizing is set while the volume is being expanded by its CSI driver
+	Resizing bool `json:"resizing,omitempty"`
+	// FileSystemResizePending is set when the volume was expanded but the
+	// filesystem will only grow the next time a pod mounts it
+	FileSystemResizePending bool `json:"fileSystemResizePending,omitempty"`
+}
+
+// GetClaimSizes returns the requested, status and volume sizes of the PVCs,
+// ordered by namespace and name. An empty namespace searches all namespaces.
+func (c *Client) GetClaimSizes(namespace string) ([]ClaimSize, error) {
+	claimList, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing PVCs: %v", err)
+	}
+
+	// PVs are cluster-scoped, users limited to a namespace may not list them
+	volumeList, err := c.Clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Warning: could not list PVs, their capacity will not be shown: %v", err)
+		volumeList = &corev1.PersistentVolumeList{}
+	}
+
+	return claimSizes(claimList.Items, volumeList.Items), nil
+}
+
+// claimSizes collects the sizes of each claim and of the volume it is bound to
+func claimSizes(claims []corev1.PersistentVolumeClaim, volumes []corev1.PersistentVolume) []ClaimSize {
+	volumeBytes := make(map[string]int64)
+	for _, pv := range volumes {
+		if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+			volumeBytes[pv.Name] = capacity.Value()
+		}
+	}
+
+	sizes := make([]ClaimSize, 0, len(claims))
+	for _, claim := range claims {
+		size := ClaimSize{
+			Namespace:   claim.Namespace,
+			PVC:         claim.Name,
+			Volume:      claim.Spec.VolumeName,
+			VolumeBytes: volumeBytes[claim.Spec.VolumeName],
+		}
+		if claim.Spec.StorageClassName != nil {
+			size.StorageClass = *claim.Spec.StorageClassName
+		}
+		if request, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			size.RequestedBytes = request.Value()
+		}
+		if capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok {
+			size.StatusBytes = capacity.Value()
+		}
+		for _, condition := range claim.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case corev1.PersistentVolumeClaimResizing:
+				size.Resizing = true
+			case corev1.PersistentVolumeClaimFileSystemResizePending:
+				size.FileSystemResizePending = true
+			}
+		}
+		sizes = append(sizes, size)
+	}
+
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].Namespace != sizes[j].Namespace {
+			return sizes[i].Namespace < sizes[j].Namespace
+		}
+		return sizes[i].PVC < sizes[j].PVC
+	})
+	return sizes
+}
+
+// GetExpandableStorageClasses returns the StorageClasses that allow volume expansion
+func (c *Client) GetExpandableStorageClasses() (map[string]bool, error) {
+	list, err := c.Clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing StorageClasses: %v", err)
+	}
+
+	expandable := make(map[string]bool)
+	for _, class := range list.Items {
+		if class.AllowVolumeExpansion != nil && *class.AllowVolumeExpansion {
+			expandable[class.Name] = true
+		}
+	}
+	return expandable, nil
+}
@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClaimSizes(t *testing.T) {
+	storage := func(quantity string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(quantity)}
+	}
+
+	expanded := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "kafka", Name: "data-0"}}
+	expanded.Spec.VolumeName = "pv-data-0"
+	storageClass := "gp3"
+	expanded.Spec.StorageClassName = &storageClass
+	expanded.Spec.Resources.Requests = storage("20Gi")
+	expanded.Status.Capacity = storage("10Gi")
+	expanded.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimResizing, Status: corev1.ConditionFalse},
+		{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+	}
+	pending := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "pending"}}
+	pending.Spec.Resources.Requests = storage("1Gi")
+
+	volume := corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-data-0"}}
+	volume.Spec.Capacity = storage("20Gi")
+
+	sizes := claimSizes([]corev1.PersistentVolumeClaim{expanded, pending}, []corev1.PersistentVolume{volume})
+	expected := []ClaimSize{
+		{Namespace: "db", PVC: "pending", RequestedBytes: 1 << 30},
+		{Namespace: "kafka", PVC: "data-0", Volume: "pv-data-0", StorageClass: "gp3", RequestedBytes: 20 << 30, StatusBytes: 10 << 30,
+			VolumeBytes: 20 << 30, FileSystemResizePending: true},
+	}
+	if len(sizes) != len(expected) {
+		t.Fatalf("claimSizes() returned %d claims, want %d: %+v", len(sizes), len(expected), sizes)
+	}
+	for i := range expected {
+		if sizes[i] != expected[i] {
+			t.Errorf("claimSizes()[%d] = %+v, want %+v", i, sizes[i], expected[i])
+		}
+	}
+}
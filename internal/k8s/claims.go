@@ -0,0 +1,160 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodVolumes are the volumes mounted by the pods of a namespace, gathered
+// from a single pod list
+type PodVolumes struct {
+	// Nodes host at least one mounted PVC, or inline volume if requested
+	Nodes []string
+	// Consumers are the pods and workload mounting each PVC
+	Consumers map[ClaimKey]*ClaimConsumer
+	// Inline holds the emptyDir and CSI inline volumes, if requested
+	Inline map[InlineVolumeKey]InlineVolume
+}
+
+// GetPodVolumes lists the pods of a namespace once to find the nodes hosting
+// their volumes, the consumers of each PVC and, if inline is set, their emptyDir
+// and CSI inline volumes. Nodes are also discovered from attached
+// VolumeAttachments. If nodeSelector is set, only matching nodes are returned.
+// An empty namespace searches all namespaces.
+func (c *Client) GetPodVolumes(namespace, nodeSelector string, inline bool) (*PodVolumes, error) {
+	nodeList, err := c.Clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: nodeSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %v", err)
+	}
+
+	podList, err := c.Clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+
+	// VolumeAttachments are only a hint, so don't fail if we can't read them
+	var attachments []storagev1.VolumeAttachment
+	vaList, err := c.Clientset.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Warning: could not list VolumeAttachments: %v", err)
+	} else {
+		attachments = vaList.Items
+	}
+
+	withClaims := claimNodes(podList.Items, attachments, inline)
+
+	volumes := podVolumes(podList.Items, c.getOwner, inline)
+	volumes.Nodes = make([]string, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		if withClaims[node.Name] {
+			volumes.Nodes = append(volumes.Nodes, node.Name)
+		}
+	}
+	return volumes, nil
+}
+
+// podVolumes collects the consumers of each PVC and, if inline is set, the
+// inline volumes of pods, resolving the workload of each pod once
+func podVolumes(pods []corev1.Pod, get ownerGetter, inline bool) *PodVolumes {
+	resolved := make(map[string]Workload)
+	volumes := &PodVolumes{
+		Consumers: make(map[ClaimKey]*ClaimConsumer),
+		Inline:    make(map[InlineVolumeKey]InlineVolume),
+	}
+	for i := range pods {
+		pod := &pods[i]
+		claims := PodClaimNames(pod)
+		var inlineVolumes map[string]InlineVolume
+		if inline {
+			inlineVolumes = podInlineVolumes(pod)
+		}
+		if len(claims) == 0 && len(inlineVolumes) == 0 {
+			continue
+		}
+
+		workload := resolveWorkload(pod, get, resolved)
+		ephemeral := ephemeralClaimNames(pod)
+		for _, claim := range claims {
+			key := ClaimKey{Namespace: pod.Namespace, Name: claim}
+			consumer, ok := volumes.Consumers[key]
+			if !ok {
+				consumer = &ClaimConsumer{Workload: workload, Ephemeral: ephemeral[claim]}
+				volumes.Consumers[key] = consumer
+			}
+			consumer.Pods = append(consumer.Pods, pod.Name)
+		}
+		for name, volume := range inlineVolumes {
+			volume.Workload = workload
+			volumes.Inline[InlineVolumeKey{Namespace: pod.Namespace, Pod: pod.Name, Volume: name}] = volume
+		}
+	}
+	return volumes
+}
+
+// claimNodes returns the set of node names that host a mounted claim, or an
+// emptyDir or CSI inline volume if inline is set
+func claimNodes(pods []corev1.Pod, attachments []storagev1.VolumeAttachment, inline bool) map[string]bool {
+	nodes := make(map[string]bool)
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		// Finished pods no longer have their volumes mounted
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			isInline := volume.EmptyDir != nil || volume.CSI != nil
+			if volume.PersistentVolumeClaim != nil || volume.Ephemeral != nil || (inline && isInline) {
+				nodes[pod.Spec.NodeName] = true
+				break
+			}
+		}
+	}
+
+	for _, va := range attachments {
+		if va.Status.Attached && va.Spec.NodeName != "" {
+			nodes[va.Spec.NodeName] = true
+		}
+	}
+
+	return nodes
+}
+
+// ListPVCNames returns the names of the PVCs in a namespace matching a label selector
+func (c *Client) ListPVCNames(namespace, selector string) ([]string, error) {
+	list, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing PVCs: %v", err)
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, claim := range list.Items {
+		names = append(names, claim.Name)
+	}
+	return names, nil
+}
+
+// ListNamespaceNames returns the names of every namespace
+func (c *Client) ListNamespaceNames() ([]string, error) {
+	list, err := c.Clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing namespaces: %v", err)
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, namespace := range list.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClaimNodes(t *testing.T) {
+	pvcVolume := corev1.Volume{
+		Name: "data",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-0"},
+		},
+	}
+	ephemeralVolume := corev1.Volume{
+		Name:         "scratch",
+		VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}},
+	}
+	emptyDirVolume := corev1.Volume{
+		Name:         "tmp",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}
+
+	pods := []corev1.Pod{
+		{Spec: corev1.PodSpec{NodeName: "worker-1", Volumes: []corev1.Volume{pvcVolume}}},
+		{Spec: corev1.PodSpec{NodeName: "worker-2", Volumes: []corev1.Volume{ephemeralVolume}}},
+		{Spec: corev1.PodSpec{NodeName: "control-plane", Volumes: []corev1.Volume{emptyDirVolume}}},
+		{Spec: corev1.PodSpec{Volumes: []corev1.Volume{pvcVolume}}}, // not scheduled yet
+		{
+			Spec:   corev1.PodSpec{NodeName: "gpu-1", Volumes: []corev1.Volume{pvcVolume}},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	}
+	attachments := []storagev1.VolumeAttachment{
+		{Spec: storagev1.VolumeAttachmentSpec{NodeName: "worker-3"}, Status: storagev1.VolumeAttachmentStatus{Attached: true}},
+		{Spec: storagev1.VolumeAttachmentSpec{NodeName: "worker-4"}, Status: storagev1.VolumeAttachmentStatus{Attached: false}},
+	}
+
+	got := claimNodes(pods, attachments, false)
+
+	want := map[string]bool{"worker-1": true, "worker-2": true, "worker-3": true}
+	if len(got) != len(want) {
+		t.Errorf("claimNodes() = %v, want %v", got, want)
+	}
+	for node := range want {
+		if !got[node] {
+			t.Errorf("claimNodes() missing node %q", node)
+		}
+	}
+}
+
+func TestClaimNodesInline(t *testing.T) {
+	pods := []corev1.Pod{
+		{Spec: corev1.PodSpec{NodeName: "worker-1", Volumes: []corev1.Volume{
+			{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		}}},
+		{Spec: corev1.PodSpec{NodeName: "worker-2", Volumes: []corev1.Volume{
+			{Name: "secrets", VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{Driver: "secrets-store.csi.k8s.io"}}},
+		}}},
+		{Spec: corev1.PodSpec{NodeName: "worker-3", Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+		}}},
+	}
+
+	got := claimNodes(pods, nil, true)
+
+	want := map[string]bool{"worker-1": true, "worker-2": true}
+	if len(got) != len(want) || !got["worker-1"] || !got["worker-2"] {
+		t.Errorf("claimNodes() = %v, want %v", got, want)
+	}
+}
+
+func TestPodVolumes(t *testing.T) {
+	pod := func(name string, volumes ...corev1.Volume) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: name, OwnerReferences: controllerRef("ReplicaSet", "api-7d9f")},
+			Spec:       corev1.PodSpec{Volumes: volumes},
+		}
+	}
+	uploads := corev1.Volume{Name: "uploads", VolumeSource: corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "uploads"},
+	}}
+	cache := corev1.Volume{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	pods := []corev1.Pod{pod("api-7d9f-a", uploads, cache), pod("api-7d9f-b", uploads), pod("api-7d9f-c", cache)}
+
+	lookups := 0
+	get := func(namespace, kind, name string) (metav1.Object, error) {
+		lookups++
+		if kind == "ReplicaSet" {
+			return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: name, OwnerReferences: controllerRef("Deployment", "api")}}, nil
+		}
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+	}
+
+	volumes := podVolumes(pods, get, true)
+	consumer := volumes.Consumers[ClaimKey{Namespace: "web", Name: "uploads"}]
+	if consumer == nil || len(consumer.Pods) != 2 || consumer.Workload.String() != "Deployment/api" {
+		t.Errorf("podVolumes() consumer = %+v, want both pods of Deployment/api", consumer)
+	}
+	if len(volumes.Inline) != 2 || volumes.Inline[InlineVolumeKey{Namespace: "web", Pod: "api-7d9f-c", Volume: "cache"}].Workload.String() != "Deployment/api" {
+		t.Errorf("podVolumes() inline = %+v, want the cache of both pods", volumes.Inline)
+	}
+	// The owners shared by the claims and inline volumes are fetched once
+	if lookups != 2 {
+		t.Errorf("podVolumes() looked up %d owners, want 2", lookups)
+	}
+
+	if volumes := podVolumes(pods, get, false); len(volumes.Inline) != 0 || len(volumes.Consumers) != 1 {
+		t.Errorf("podVolumes() without inline = %+v, want only the consumers", volumes)
+	}
+}
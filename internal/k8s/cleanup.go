@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// MonitorAppLabel is the app label value of performance monitor pods
+	MonitorAppLabel = "pvc-perf-monitor"
+	// HeartbeatAnnotation holds the unix time of the last heartbeat from pvcusage
+	HeartbeatAnnotation = "pvcusage.io/heartbeat"
+	// HeartbeatInterval is how often a running pvcusage refreshes the heartbeat
+	HeartbeatInterval = 30 * time.Second
+	// HeartbeatTimeout is how long a monitor pod keeps running without a heartbeat
+	HeartbeatTimeout = 3 * time.Minute
+)
+
+// heartbeatValue formats a heartbeat annotation value
+func heartbeatValue(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// Heartbeat refreshes the heartbeat annotation of a monitor pod
+func (c *Client) Heartbeat(namespace, podName string) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, HeartbeatAnnotation, heartbeatValue(time.Now()))
+	_, err := c.Clientset.CoreV1().Pods(namespace).Patch(context.TODO(), podName,
+		types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("error updating heartbeat: %v", err)
+	}
+	return nil
+}
+
+// CleanupMonitorPods deletes performance monitor pods left behind by pvcusage.
+// An empty namespace searches all namespaces. With olderThan set, only pods
+// idle for longer than that are deleted. With dryRun set, nothing is deleted.
+// It returns the namespace/name of the matching pods.
+func (c *Client) CleanupMonitorPods(namespace string, olderThan time.Duration, dryRun bool) ([]string, error) {
+	pods, err := c.Clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: "app=" + MonitorAppLabel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing monitor pods: %v", err)
+	}
+
+	now := time.Now()
+	var deleted []string
+	for _, pod := range pods.Items {
+		if olderThan > 0 && monitorIdleTime(&pod, now) < olderThan {
+			continue
+		}
+
+		if !dryRun {
+			err := c.Clientset.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{
+				GracePeriodSeconds: int64Ptr(0),
+			})
+			if err != nil {
+				return deleted, fmt.Errorf("error deleting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+		}
+		deleted = append(deleted, pod.Namespace+"/"+pod.Name)
+	}
+	return deleted, nil
+}
+
+// monitorIdleTime returns the time since the last heartbeat of a monitor pod,
+// or since its creation if it never had one
+func monitorIdleTime(pod *corev1.Pod, now time.Time) time.Duration {
+	last := pod.CreationTimestamp.Time
+	if value, ok := pod.Annotations[HeartbeatAnnotation]; ok {
+		if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+			last = time.Unix(unix, 0)
+		}
+	}
+	return now.Sub(last)
+}
@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMonitorIdleTime(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-2 * time.Hour))
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Duration
+	}{
+		{"no heartbeat", nil, 2 * time.Hour},
+		{"recent heartbeat", map[string]string{HeartbeatAnnotation: strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)}, time.Minute},
+		{"invalid heartbeat", map[string]string{HeartbeatAnnotation: "soon"}, 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created, Annotations: tt.annotations}}
+		if got := monitorIdleTime(pod, now); got != tt.want {
+			t.Errorf("monitorIdleTime(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMonitorScriptHeartbeat(t *testing.T) {
+	script := monitorScript(scriptOptions{annotationsFile: "/etc/pvcusage/annotations"})
+	if !strings.Contains(script, `s/^pvcusage.io\/heartbeat=`) || !strings.Contains(script, "/etc/pvcusage/annotations") {
+		t.Errorf("monitorScript() does not check the heartbeat:\n%s", script)
+	}
+	if !strings.Contains(script, "-lt "+strconv.Itoa(int(HeartbeatTimeout.Seconds()))) {
+		t.Errorf("monitorScript() does not use HeartbeatTimeout")
+	}
+}
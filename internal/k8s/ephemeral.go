@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// EphemeralLease is how long an injected monitor container runs unless
+// pvcusage extends its deadline. Ephemeral containers can't be removed, so
+// the container exits on its own shortly after pvcusage stops extending it.
+const EphemeralLease = 2 * time.Minute
+
+// ephemeralDeadlineFile holds the deadline written by SetEphemeralDeadline
+const ephemeralDeadlineFile = "/tmp/pvcusage-deadline"
+
+// ErrEphemeralUnsupported is returned when the cluster or RBAC doesn't allow
+// adding ephemeral containers to the consumer pod
+var ErrEphemeralUnsupported = errors.New("ephemeral containers are not allowed")
+
+// AttachPerformanceContainer injects an ephemeral monitor container into the
+// pod that mounts the PVC, through the pods/ephemeralcontainers subresource.
+// It returns the name of the injected container.
+func (c *Client) AttachPerformanceContainer(namespace, targetPod, pvcName string, settings PodSettings) (string, error) {
+	pod, err := c.Clientset.CoreV1().Pods(namespace).Get(context.TODO(), targetPod, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting target pod: %v", err)
+	}
+
+	volumeName := claimVolumeName(pod, pvcName)
+	if volumeName == "" {
+		return "", fmt.Errorf("pod '%s' does not mount PVC '%s'", targetPod, pvcName)
+	}
+
+	// Names can't be reused within a pod, even after the container exits
+	name := fmt.Sprintf("pvc-perf-%d", time.Now().Unix())
+	deadline := time.Now().Add(EphemeralLease).Unix()
+	container, err := ephemeralMonitorContainer(name, volumeName, deadline, settings)
+	if err != nil {
+		return "", fmt.Errorf("invalid performance pod settings: %v", err)
+	}
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, container)
+	_, err = c.Clientset.CoreV1().Pods(namespace).UpdateEphemeralContainers(context.TODO(), targetPod, pod, metav1.UpdateOptions{})
+	if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+		return "", fmt.Errorf("%w: %v", ErrEphemeralUnsupported, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error adding ephemeral container: %v", err)
+	}
+
+	return name, nil
+}
+
+// SetEphemeralDeadline moves the time at which an injected monitor container
+// exits, through pods/exec. A deadline in the past stops it after its current loop.
+func (c *Client) SetEphemeralDeadline(namespace, podName, container string, deadline time.Time) error {
+	req := c.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(podName).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   []string{"sh", "-c", fmt.Sprintf("echo %d > %s", deadline.Unix(), ephemeralDeadlineFile)},
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.config, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("error setting the deadline of container %s: %v", container, err)
+	}
+	var stderr bytes.Buffer
+	if err := executor.StreamWithContext(context.TODO(), remotecommand.StreamOptions{Stderr: &stderr}); err != nil {
+		return fmt.Errorf("error setting the deadline of container %s: %v %s", container, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// claimVolumeName returns the name of the pod volume backed by the PVC
+func claimVolumeName(pod *corev1.Pod, pvcName string) string {
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvcName:
+			return volume.Name
+		case volume.Ephemeral != nil && pod.Name+"-"+volume.Name == pvcName:
+			return volume.Name
+		}
+	}
+	return ""
+}
+
+// ephemeralMonitorContainer builds the monitor as an ephemeral container that
+// reuses the pod's own mount of the volume
+func ephemeralMonitorContainer(name, volumeName string, deadline int64, settings PodSettings) (corev1.EphemeralContainer, error) {
+	seccomp, err := settings.seccompProfile()
+	if err != nil {
+		return corev1.EphemeralContainer{}, err
+	}
+
+	// Ephemeral containers can't set resources, so only the security settings apply
+	securityContext := &corev1.SecurityContext{
+		Privileged:               boolPtr(false),
+		AllowPrivilegeEscalation: boolPtr(false),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		RunAsNonRoot:             boolPtr(settings.RunAsNonRoot),
+		SeccompProfile:           seccomp,
+	}
+	if settings.RunAsUser != 0 {
+		securityContext.RunAsUser = int64Ptr(settings.RunAsUser)
+	}
+
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:  name,
+			Image: settings.Image,
+			// The dd throughput loop is left out, as it would burn CPU in the
+			// application's pod, which ephemeral containers can't set limits for
+			Command: []string{"sh", "-c", monitorScript(scriptOptions{deadline: deadline, deadlineFile: ephemeralDeadlineFile})},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      volumeName,
+					MountPath: "/mnt/pvc",
+					ReadOnly:  true, // Mount as read-only to prevent any writes
+				},
+			},
+			SecurityContext: securityContext,
+		},
+	}, nil
+}
@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClaimVolumeName(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "builder"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "uploads"},
+			}},
+			{Name: "cache", VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}}},
+		}},
+	}
+
+	tests := map[string]string{
+		"uploads":       "data",
+		"builder-cache": "cache",
+		"other":         "",
+	}
+	for claim, want := range tests {
+		if got := claimVolumeName(pod, claim); got != want {
+			t.Errorf("claimVolumeName(%q) = %q, want %q", claim, got, want)
+		}
+	}
+}
+
+func TestEphemeralMonitorContainer(t *testing.T) {
+	container, err := ephemeralMonitorContainer("pvc-perf-1", "data", 1700000000, DefaultPodSettings())
+	if err != nil {
+		t.Fatalf("ephemeralMonitorContainer() unexpected error: %v", err)
+	}
+
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != "data" || !container.VolumeMounts[0].ReadOnly {
+		t.Errorf("VolumeMounts = %v, want read-only mount of volume data", container.VolumeMounts)
+	}
+	if container.SecurityContext.SeccompProfile == nil {
+		t.Errorf("SecurityContext.SeccompProfile not set")
+	}
+	script := container.Command[2]
+	if !strings.Contains(script, "|| echo 1700000000") || !strings.Contains(script, ephemeralDeadlineFile) {
+		t.Errorf("script does not stop at the extendable deadline:\n%s", script)
+	}
+	if strings.Contains(script, "dd if=") {
+		t.Errorf("script runs the dd throughput loop in the application's pod:\n%s", script)
+	}
+
+	settings := DefaultPodSettings()
+	settings.SeccompProfile = "Custom"
+	if _, err := ephemeralMonitorContainer("pvc-perf-1", "data", 1, settings); err == nil {
+		t.Errorf("ephemeralMonitorContainer() expected error for invalid seccomp profile")
+	}
+}
+
+func TestMonitorScript(t *testing.T) {
+	if script := monitorScript(scriptOptions{throughput: true}); !strings.Contains(script, "while true; do") || !strings.Contains(script, "THROUGHPUT_BEGIN") {
+		t.Errorf("monitorScript() should loop forever with the throughput section:\n%s", script)
+	}
+	for _, marker := range []string{"DISK_USAGE_BEGIN", "IO_STATS_END", "-lt 42 ]"} {
+		if !strings.Contains(monitorScript(scriptOptions{deadline: 42}), marker) {
+			t.Errorf("monitorScript(deadline 42) is missing %q", marker)
+		}
+	}
+}
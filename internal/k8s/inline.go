@@ -0,0 +1,44 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Types of the pod volumes that are not backed by a PVC
+const (
+	InlineEmptyDir = "emptyDir"
+	InlineCSI      = "csi"
+)
+
+// InlineVolumeKey identifies a volume of a pod
+type InlineVolumeKey struct {
+	Namespace string
+	Pod       string
+	Volume    string
+}
+
+// InlineVolume is an emptyDir or CSI inline volume of a pod
+type InlineVolume struct {
+	Type           string
+	SizeLimitBytes int64 // emptyDir sizeLimit, 0 when unset
+	Workload       Workload
+}
+
+// podInlineVolumes returns the emptyDir and CSI inline volumes of a pod by name.
+// Projected volumes like service account tokens are left out.
+func podInlineVolumes(pod *corev1.Pod) map[string]InlineVolume {
+	volumes := make(map[string]InlineVolume)
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.EmptyDir != nil:
+			inline := InlineVolume{Type: InlineEmptyDir}
+			if volume.EmptyDir.SizeLimit != nil {
+				inline.SizeLimitBytes = volume.EmptyDir.SizeLimit.Value()
+			}
+			volumes[volume.Name] = inline
+		case volume.CSI != nil:
+			volumes[volume.Name] = InlineVolume{Type: InlineCSI}
+		}
+	}
+	return volumes
+}
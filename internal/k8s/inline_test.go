@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodInlineVolumes(t *testing.T) {
+	limit := resource.MustParse("2Gi")
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0"},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-web-0"},
+				}},
+				{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &limit}}},
+				{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "secrets", VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{Driver: "secrets-store.csi.k8s.io"}}},
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}}},
+				{Name: "token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{}}},
+			},
+		},
+	}
+
+	expected := map[string]InlineVolume{
+		"cache":   {Type: InlineEmptyDir, SizeLimitBytes: 2 << 30},
+		"tmp":     {Type: InlineEmptyDir},
+		"secrets": {Type: InlineCSI},
+	}
+	if got := podInlineVolumes(pod); !reflect.DeepEqual(got, expected) {
+		t.Errorf("podInlineVolumes() = %+v, want %+v", got, expected)
+	}
+	if got := ephemeralClaimNames(pod); !reflect.DeepEqual(got, map[string]bool{"web-0-scratch": true}) {
+		t.Errorf("ephemeralClaimNames() = %v, want web-0-scratch", got)
+	}
+}
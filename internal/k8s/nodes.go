@@ -3,40 +3,42 @@ package k8s
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // Client wraps the Kubernetes client and configuration
 type Client struct {
 	Clientset *kubernetes.Clientset
-}
+	// Dynamic reads resources without typed clients, such as VolumeSnapshots
+	Dynamic dynamic.Interface
 
-// NewClient creates a new Kubernetes client
-func NewClient() (*Client, error) {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		kubeconfig := clientcmd.RecommendedHomeFile
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
-		if err != nil {
-			return nil, fmt.Errorf("error building kubeconfig: %v", err)
-		}
-	}
+	config *rest.Config // for exec streams
+}
 
+// NewClient creates a new Kubernetes client from a REST config, usually built
+// from the kubeconfig and kubectl-style flags
+func NewClient(config *rest.Config) (*Client, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("error creating Kubernetes client: %v", err)
 	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %v", err)
+	}
 
-	return &Client{Clientset: clientset}, nil
+	return &Client{Clientset: clientset, Dynamic: dynamicClient, config: config}, nil
 }
 
 // GetNodes returns the list of node names
@@ -67,109 +69,155 @@ func (c *Client) GetSummary(node string) (*Summary, error) {
 	return &s, nil
 }
 
-// FindPodUsingPVC finds a pod that uses the specified PVC or any pod in the namespace if no direct match
-func (c *Client) FindPodUsingPVC(namespace, pvcName string) (string, error) {
-	// Get all pods in the namespace
+// ErrNoConsumer is returned when no pod uses a PVC
+var ErrNoConsumer = errors.New("no pod uses the PVC")
+
+// FindPodUsingPVC finds the pod that mounts the specified PVC. Consumers are
+// looked up through pod volumes (including generic ephemeral volumes) and
+// StatefulSet volumeClaimTemplates. If nothing uses the PVC an error is returned,
+// unless guess is set, in which case name-based candidates are listed and the
+// first one is used.
+func (c *Client) FindPodUsingPVC(namespace, pvcName string, guess bool) (string, error) {
+	claim, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting PVC '%s' in namespace '%s': %v", pvcName, namespace, err)
+	}
+
 	pods, err := c.Clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return "", fmt.Errorf("error listing pods: %v", err)
 	}
 
-	if len(pods.Items) == 0 {
-		return "", fmt.Errorf("no pods found in namespace '%s'", namespace)
+	statefulSets, err := c.Clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error listing statefulsets: %v", err)
 	}
 
-	fmt.Printf("Searching for pod using PVC '%s' in namespace '%s'...\n", pvcName, namespace)
+	if pod := findConsumerPod(pods.Items, claim, statefulSets.Items); pod != "" {
+		return pod, nil
+	}
 
-	// First look for a pod that directly uses the PVC
-	for _, pod := range pods.Items {
-		for _, volume := range pod.Spec.Volumes {
-			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvcName {
-				fmt.Printf("Found pod '%s' directly using the PVC\n", pod.Name)
-				return pod.Name, nil
-			}
-		}
+	if !guess {
+		return "", fmt.Errorf("%w '%s' in namespace '%s'", ErrNoConsumer, pvcName, namespace)
+	}
+
+	candidates := guessPodCandidates(pods.Items, pvcName)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%w '%s' in namespace '%s' and no candidate could be guessed", ErrNoConsumer, pvcName, namespace)
 	}
 
-	fmt.Println("No pod found directly using the PVC. Trying to infer pod based on naming patterns...")
+	fmt.Printf("No pod uses PVC '%s'. Guessed candidates:\n", pvcName)
+	for _, candidate := range candidates {
+		fmt.Printf("  %s (%s)\n", candidate.Name, candidate.Reason)
+	}
+	fmt.Printf("Using guessed pod '%s'\n", candidates[0].Name)
+	return candidates[0].Name, nil
+}
 
-	// Try to infer the pod from the PVC name for StatefulSets/Strimzi/Kafka
+// PodCandidate is a pod guessed to use a PVC, with the reason it was picked
+type PodCandidate struct {
+	Name   string
+	Reason string
+}
 
-	// Pattern 1: data-0-kafka-kafka-pool-0
-	// This looks like Strimzi Kafka PVC pattern
-	if strings.HasPrefix(pvcName, "data-") {
-		parts := strings.Split(pvcName, "-")
+// findConsumerPod returns the pod that mounts the claim, preferring running pods.
+// It returns an empty string when no pod uses the claim.
+func findConsumerPod(pods []corev1.Pod, claim *corev1.PersistentVolumeClaim, statefulSets []appsv1.StatefulSet) string {
+	var consumers []*corev1.Pod
+	byName := make(map[string]*corev1.Pod, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		byName[pod.Name] = pod
+		for _, name := range PodClaimNames(pod) {
+			if name == claim.Name {
+				consumers = append(consumers, pod)
+				break
+			}
+		}
+	}
 
-		// Try different patterns to match pods
-		var patterns []string
+	// Claims generated for generic ephemeral volumes are controlled by their pod
+	if ref := metav1.GetControllerOf(claim); ref != nil && ref.Kind == "Pod" {
+		if pod, ok := byName[ref.Name]; ok {
+			consumers = append(consumers, pod)
+		}
+	}
 
-		// If format is data-X-cluster-something
-		if len(parts) >= 4 {
-			ordinal := parts[1] // e.g., "0", "1", etc.
-			cluster := parts[2] // e.g., "kafkasomething"
+	// StatefulSet claims are named <template>-<statefulset>-<ordinal> and belong to pod <statefulset>-<ordinal>
+	for _, sts := range statefulSets {
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			prefix := template.Name + "-" + sts.Name + "-"
+			ordinal := strings.TrimPrefix(claim.Name, prefix)
+			if ordinal == claim.Name {
+				continue
+			}
+			if _, err := strconv.Atoi(ordinal); err != nil {
+				continue
+			}
+			if pod, ok := byName[sts.Name+"-"+ordinal]; ok {
+				consumers = append(consumers, pod)
+			}
+		}
+	}
 
-			// Common Strimzi Kafka pod naming patterns
-			patterns = append(patterns, []string{
-				fmt.Sprintf("%s-%s", cluster, ordinal),
-				fmt.Sprintf("%s-%s", parts[2], ordinal),
-				fmt.Sprintf("%s-%s-%s", parts[2], parts[3], ordinal),
-				fmt.Sprintf("%s-kafka-%s", cluster, ordinal),
-				fmt.Sprintf("%s-zookeeper-%s", cluster, ordinal),
-			}...)
+	for _, pod := range consumers {
+		if pod.Status.Phase == corev1.PodRunning {
+			return pod.Name
 		}
+	}
+	if len(consumers) > 0 {
+		return consumers[0].Name
+	}
+	return ""
+}
 
-		fmt.Printf("Looking for pods with patterns: %v\n", patterns)
+// guessPodCandidates lists pods that might use a PVC based on naming conventions only.
+// Candidates are ordered from the most to the least specific match.
+func guessPodCandidates(pods []corev1.Pod, pvcName string) []PodCandidate {
+	var candidates []PodCandidate
+	seen := make(map[string]bool)
+	add := func(name, reason string) {
+		if !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, PodCandidate{Name: name, Reason: reason})
+		}
+	}
 
-		// Check if any pod matches our patterns
+	// Strimzi/Kafka style claims: data-<ordinal>-<cluster>-<pool>-<ordinal>
+	parts := strings.Split(pvcName, "-")
+	if strings.HasPrefix(pvcName, "data-") && len(parts) >= 4 {
+		ordinal := parts[1]
+		cluster := parts[2]
+		patterns := []string{
+			fmt.Sprintf("%s-%s", cluster, ordinal),
+			fmt.Sprintf("%s-%s-%s", cluster, parts[3], ordinal),
+			fmt.Sprintf("%s-kafka-%s", cluster, ordinal),
+			fmt.Sprintf("%s-zookeeper-%s", cluster, ordinal),
+		}
 		for _, pattern := range patterns {
-			for _, pod := range pods.Items {
+			for _, pod := range pods {
 				if strings.HasPrefix(pod.Name, pattern) {
-					fmt.Printf("Found pod '%s' matching pattern '%s'\n", pod.Name, pattern)
-					return pod.Name, nil
+					add(pod.Name, fmt.Sprintf("matches pattern '%s'", pattern))
 				}
 			}
 		}
 	}
 
-	// Look for pods that contain parts of the PVC name
-	for _, pod := range pods.Items {
-		// Split PVC name and pod name by '-'
-		pvcParts := strings.Split(pvcName, "-")
-		podParts := strings.Split(pod.Name, "-")
-
-		// If any significant part matches, use that pod
-		for _, pvcPart := range pvcParts {
-			if len(pvcPart) > 3 { // Only consider significant parts (longer than 3 chars)
-				for _, podPart := range podParts {
-					if pvcPart == podPart && len(podPart) > 3 {
-						fmt.Printf("Found pod '%s' sharing common part '%s' with PVC\n", pod.Name, pvcPart)
-						return pod.Name, nil
-					}
+	// Pods sharing a significant name fragment with the PVC
+	for _, pod := range pods {
+		for _, pvcPart := range parts {
+			if len(pvcPart) <= 3 {
+				continue
+			}
+			for _, podPart := range strings.Split(pod.Name, "-") {
+				if pvcPart == podPart {
+					add(pod.Name, fmt.Sprintf("shares name fragment '%s'", pvcPart))
 				}
 			}
 		}
 	}
 
-	// If all else fails, just use any running pod (preferring pods with "kafka" in the name)
-	for _, pod := range pods.Items {
-		if strings.Contains(strings.ToLower(pod.Name), "kafka") &&
-			pod.Status.Phase == corev1.PodRunning {
-			fmt.Printf("Using pod '%s' as it contains 'kafka' in the name\n", pod.Name)
-			return pod.Name, nil
-		}
-	}
-
-	// Last resort: use any running pod
-	for _, pod := range pods.Items {
-		if pod.Status.Phase == corev1.PodRunning {
-			fmt.Printf("Using pod '%s' as fallback (first running pod found)\n", pod.Name)
-			return pod.Name, nil
-		}
-	}
-
-	// Absolute last resort: use any pod
-	fmt.Printf("Using pod '%s' as last resort\n", pods.Items[0].Name)
-	return pods.Items[0].Name, nil
+	return candidates
 }
 
 // int64Ptr returns a pointer to an int64
@@ -182,12 +230,25 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-// CreatePerformancePod creates a sidecar pod to monitor performance of a PVC
-func (c *Client) CreatePerformancePod(namespace, targetPod, pvcName string) (string, error) {
-	// Get target pod details
-	pod, err := c.Clientset.CoreV1().Pods(namespace).Get(context.TODO(), targetPod, metav1.GetOptions{})
-	if err != nil {
-		return "", fmt.Errorf("error getting target pod: %v", err)
+// CreatePerformancePod creates a sidecar pod to monitor performance of a PVC.
+// The pod runs on the same node as targetPod. If targetPod is empty (the PVC
+// isn't mounted), it is scheduled using the PersistentVolume's placement instead.
+func (c *Client) CreatePerformancePod(namespace, targetPod, pvcName string, settings PodSettings) (string, error) {
+	var nodeName string
+	var affinity *corev1.Affinity
+	if targetPod != "" {
+		// Get target pod details
+		pod, err := c.Clientset.CoreV1().Pods(namespace).Get(context.TODO(), targetPod, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("error getting target pod: %v", err)
+		}
+		nodeName = pod.Spec.NodeName
+	} else {
+		var err error
+		affinity, err = c.volumeAffinity(namespace, pvcName)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	// Create a name for the performance pod - truncate if longer than 50 chars to leave room for prefix
@@ -207,7 +268,7 @@ func (c *Client) CreatePerformancePod(namespace, targetPod, pvcName string) (str
 	}
 
 	// Check if performance pod already exists and delete it if it does
-	_, err = c.Clientset.CoreV1().Pods(namespace).Get(context.TODO(), perfPodName, metav1.GetOptions{})
+	_, err := c.Clientset.CoreV1().Pods(namespace).Get(context.TODO(), perfPodName, metav1.GetOptions{})
 	if err == nil {
 		// Pod exists, delete it
 		err = c.Clientset.CoreV1().Pods(namespace).Delete(context.TODO(), perfPodName, metav1.DeleteOptions{})
@@ -253,75 +314,31 @@ func (c *Client) CreatePerformancePod(namespace, targetPod, pvcName string) (str
 			Name:      perfPodName,
 			Namespace: namespace,
 			Labels: map[string]string{
-				"app":        "pvc-perf-monitor",
+				"app":        MonitorAppLabel,
 				"target-pod": safeTargetPod,
 				"pvc-name":   safePVCNameLabel,
 			},
+			Annotations: map[string]string{
+				HeartbeatAnnotation: heartbeatValue(time.Now()),
+			},
 		},
 		Spec: corev1.PodSpec{
-			NodeName: pod.Spec.NodeName, // Ensure we run on the same node to access the PVC
+			NodeName: nodeName, // Ensure we run on the same node to access the PVC
+			Affinity: affinity, // Or wherever the volume is reachable when it isn't mounted
 			Containers: []corev1.Container{
 				{
-					Name:  "perf-monitor",
-					Image: "nicolaka/netshoot", // Container with network and IO diagnostic tools
-					Command: []string{
-						"sh",
-						"-c",
-						`echo "Starting PVC Performance Monitor (Read-Only mode)"
-						if [ -d "/mnt/pvc" ]; then
-							echo "PVC mounted successfully at /mnt/pvc in read-only mode"
-							# Show basic info about the mount
-							df -h /mnt/pvc
-							mount | grep /mnt/pvc
-							
-							# In loop, monitor the PVC
-							while true; do
-								echo "------- PVC Monitor $(date) -------"
-								# Show disk usage in a parseable format
-								echo "DISK_USAGE_BEGIN"
-								df -h /mnt/pvc | grep -v "Filesystem"
-								echo "DISK_USAGE_END"
-								
-								# System stats
-								echo "SYSTEM_STATS_BEGIN"
-								top -bn1 | grep "load average:" | head -1
-								echo "SYSTEM_STATS_END"
-								
-								# IO stats
-								echo "IO_STATS_BEGIN"
-								iostat -dxh 1 1 | grep -v "loop\|ram"
-								echo "IO_STATS_END"
-								
-								# Disk throughput estimation
-								echo "THROUGHPUT_BEGIN"
-								dd if=/dev/zero of=/dev/null bs=1M count=1000 2>&1 | grep -i "bytes"
-								echo "THROUGHPUT_END"
-								
-								sleep 1
-							done
-						else
-							echo "Error: PVC directory /mnt/pvc not found"
-							exit 1
-						fi`,
-					},
+					Name:    "perf-monitor",
+					Command: []string{"sh", "-c", monitorScript(scriptOptions{annotationsFile: "/etc/pvcusage/annotations", throughput: true})},
 					VolumeMounts: []corev1.VolumeMount{
 						{
 							Name:      "pvc-volume",
 							MountPath: "/mnt/pvc",
 							ReadOnly:  true, // Mount as read-only to prevent any writes
 						},
-					},
-					SecurityContext: &corev1.SecurityContext{
-						Privileged: boolPtr(false),
-					},
-					Resources: corev1.ResourceRequirements{
-						Limits: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("100m"),
-							corev1.ResourceMemory: resource.MustParse("128Mi"),
-						},
-						Requests: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("50m"),
-							corev1.ResourceMemory: resource.MustParse("64Mi"),
+						{
+							Name:      "podinfo",
+							MountPath: "/etc/pvcusage",
+							ReadOnly:  true,
 						},
 					},
 				},
@@ -336,11 +353,30 @@ func (c *Client) CreatePerformancePod(namespace, targetPod, pvcName string) (str
 						},
 					},
 				},
+				{
+					// Exposes the heartbeat annotation so the monitor exits once pvcusage is gone
+					Name: "podinfo",
+					VolumeSource: corev1.VolumeSource{
+						DownwardAPI: &corev1.DownwardAPIVolumeSource{
+							Items: []corev1.DownwardAPIVolumeFile{
+								{
+									Path:     "annotations",
+									FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations"},
+								},
+							},
+						},
+					},
+				},
 			},
 			RestartPolicy: corev1.RestartPolicyNever,
 		},
 	}
 
+	// Image, resources, scheduling and security context come from the settings
+	if err := settings.Apply(&perfPod.Spec, &perfPod.Spec.Containers[0]); err != nil {
+		return "", fmt.Errorf("invalid performance pod settings: %v", err)
+	}
+
 	// Create the pod
 	createdPod, err := c.Clientset.CoreV1().Pods(namespace).Create(context.TODO(), perfPod, metav1.CreateOptions{})
 	if err != nil {
@@ -349,3 +385,71 @@ func (c *Client) CreatePerformancePod(namespace, targetPod, pvcName string) (str
 
 	return createdPod.Name, nil
 }
+
+// scriptOptions shape the monitor script
+type scriptOptions struct {
+	deadline        int64  // unix seconds at which the loop exits, 0 for none
+	deadlineFile    string // file that, once written, replaces deadline
+	annotationsFile string // downward API file holding the heartbeat annotation
+	throughput      bool   // estimate throughput with dd, which costs a CPU second per loop
+}
+
+// monitorScript returns the shell script run by the monitor container. It prints
+// df, load and iostat sections delimited by markers that perf parses from the logs.
+// A non-zero deadline makes the loop exit at that time, or at the time found in
+// deadlineFile once pvcusage has written it. If annotationsFile is set, the loop
+// also exits once the heartbeat annotation found in it is older than HeartbeatTimeout.
+func monitorScript(opts scriptOptions) string {
+	loop := "true"
+	if opts.deadline > 0 && opts.deadlineFile != "" {
+		loop = fmt.Sprintf(`[ "$(date +%%s)" -lt "$(cat %s 2>/dev/null || echo %d)" ]`, opts.deadlineFile, opts.deadline)
+	} else if opts.deadline > 0 {
+		loop = fmt.Sprintf(`[ "$(date +%%s)" -lt %d ]`, opts.deadline)
+	}
+	if opts.annotationsFile != "" {
+		loop += fmt.Sprintf(` && {
+		heartbeat=$(sed -n 's/^%s="\([0-9]*\)"$/\1/p' %s)
+		[ -z "$heartbeat" ] || [ $(( $(date +%%s) - heartbeat )) -lt %d ] || { echo "Heartbeat expired, exiting"; false; }
+	}`, strings.ReplaceAll(HeartbeatAnnotation, "/", "\\/"), opts.annotationsFile, int(HeartbeatTimeout.Seconds()))
+	}
+	throughput := ""
+	if opts.throughput {
+		throughput = `
+		# Disk throughput estimation
+		echo "THROUGHPUT_BEGIN"
+		dd if=/dev/zero of=/dev/null bs=1M count=1000 2>&1 | grep -i "bytes"
+		echo "THROUGHPUT_END"
+		`
+	}
+	return fmt.Sprintf(`echo "Starting PVC Performance Monitor (Read-Only mode)"
+if [ -d "/mnt/pvc" ]; then
+	echo "PVC mounted successfully at /mnt/pvc in read-only mode"
+	# Show basic info about the mount
+	df -h /mnt/pvc
+	mount | grep /mnt/pvc
+	
+	# In loop, monitor the PVC
+	while %s; do
+		echo "------- PVC Monitor $(date) -------"
+		# Show disk usage in a parseable format
+		echo "DISK_USAGE_BEGIN"
+		df -h /mnt/pvc | grep -v "Filesystem"
+		echo "DISK_USAGE_END"
+		
+		# System stats
+		echo "SYSTEM_STATS_BEGIN"
+		top -bn1 | grep "load average:" | head -1
+		echo "SYSTEM_STATS_END"
+		
+		# IO stats
+		echo "IO_STATS_BEGIN"
+		iostat -dxh 1 1 | grep -v "loop\|ram"
+		echo "IO_STATS_END"
+		%s
+		sleep 1
+	done
+else
+	echo "Error: PVC directory /mnt/pvc not found"
+	exit 1
+fi`, loop, throughput)
+}
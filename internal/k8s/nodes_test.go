@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podWithClaim builds a pod mounting the given claim
+func podWithClaim(name, claim string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			},
+		}}},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestFindConsumerPod(t *testing.T) {
+	claim := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	pods := []corev1.Pod{
+		podWithClaim("old-web", "uploads", corev1.PodPending),
+		podWithClaim("web", "uploads", corev1.PodRunning),
+		{ObjectMeta: metav1.ObjectMeta{Name: "db-0"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "builder"}},
+		podWithClaim("kafka-0", "data-kafka-0", corev1.PodRunning),
+	}
+	statefulSets := []appsv1.StatefulSet{{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Spec: appsv1.StatefulSetSpec{VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+			{ObjectMeta: metav1.ObjectMeta{Name: "pgdata"}},
+		}},
+	}}
+
+	ephemeral := claim("builder-cache")
+	ephemeral.OwnerReferences = controllerRef("Pod", "builder")
+
+	tests := []struct {
+		name  string
+		claim *corev1.PersistentVolumeClaim
+		want  string
+	}{
+		{"prefers running pod", claim("uploads"), "web"},
+		{"statefulset template", claim("pgdata-db-0"), "db-0"},
+		{"generic ephemeral", ephemeral, "builder"},
+		{"unused", claim("data-kafka-1"), ""},
+	}
+
+	for _, tt := range tests {
+		got := findConsumerPod(pods, tt.claim, statefulSets)
+		if got != tt.want {
+			t.Errorf("findConsumerPod(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGuessPodCandidates(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "billing-api-5f6d"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster-kafka-0"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unrelated"}},
+	}
+
+	candidates := guessPodCandidates(pods, "data-0-my-cluster-kafka-0")
+	if len(candidates) == 0 || candidates[0].Name != "my-cluster-kafka-0" {
+		t.Fatalf("guessPodCandidates() = %v, want my-cluster-kafka-0 first", candidates)
+	}
+	for _, candidate := range candidates {
+		if candidate.Name == "unrelated" {
+			t.Errorf("guessPodCandidates() included unrelated pod")
+		}
+		if candidate.Reason == "" {
+			t.Errorf("guessPodCandidates() candidate %q has no reason", candidate.Name)
+		}
+	}
+
+	if got := guessPodCandidates(pods, "x"); len(got) != 0 {
+		t.Errorf("guessPodCandidates(\"x\") = %v, want none", got)
+	}
+}
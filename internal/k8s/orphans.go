@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OrphanClaim is a PVC that no running pod mounts
+type OrphanClaim struct {
+	Namespace     string    `json:"namespace"`
+	PVC           string    `json:"pvc"`
+	Phase         string    `json:"phase"`
+	CapacityBytes int64     `json:"capacityBytes"`
+	StorageClass  string    `json:"storageClass,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// GetOrphanClaims returns the PVCs that are not mounted by any running pod,
+// ordered by namespace and name. An empty namespace searches all namespaces.
+func (c *Client) GetOrphanClaims(namespace string) ([]OrphanClaim, error) {
+	claimList, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing PVCs: %v", err)
+	}
+
+	podList, err := c.Clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+
+	return orphanClaims(claimList.Items, podList.Items), nil
+}
+
+// orphanClaims returns the claims that no pod mounts. Finished pods don't
+// count, as their volumes are no longer mounted.
+func orphanClaims(claims []corev1.PersistentVolumeClaim, pods []corev1.Pod) []OrphanClaim {
+	mounted := make(map[ClaimKey]bool)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, claim := range PodClaimNames(pod) {
+			mounted[ClaimKey{Namespace: pod.Namespace, Name: claim}] = true
+		}
+	}
+
+	var orphans []OrphanClaim
+	for _, claim := range claims {
+		if mounted[ClaimKey{Namespace: claim.Namespace, Name: claim.Name}] {
+			continue
+		}
+
+		orphan := OrphanClaim{
+			Namespace: claim.Namespace,
+			PVC:       claim.Name,
+			Phase:     string(claim.Status.Phase),
+			CreatedAt: claim.CreationTimestamp.Time,
+		}
+		// Pending claims have no capacity yet, fall back to the request
+		if capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]; ok {
+			orphan.CapacityBytes = capacity.Value()
+		} else if request, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			orphan.CapacityBytes = request.Value()
+		}
+		if claim.Spec.StorageClassName != nil {
+			orphan.StorageClass = *claim.Spec.StorageClassName
+		}
+		orphans = append(orphans, orphan)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Namespace != orphans[j].Namespace {
+			return orphans[i].Namespace < orphans[j].Namespace
+		}
+		return orphans[i].PVC < orphans[j].PVC
+	})
+	return orphans
+}
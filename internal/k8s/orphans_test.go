@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOrphanClaims(t *testing.T) {
+	claim := func(namespace, name string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+
+	bound := claim("web", "old-uploads")
+	bound.Status.Phase = corev1.ClaimBound
+	bound.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	pending := claim("web", "new-uploads")
+	pending.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}
+
+	running := podWithClaim("web", "uploads", corev1.PodRunning)
+	running.Namespace = "web"
+	finished := podWithClaim("backup", "old-uploads", corev1.PodSucceeded)
+	finished.Namespace = "web"
+	otherNamespace := podWithClaim("web", "new-uploads", corev1.PodRunning)
+	otherNamespace.Namespace = "staging"
+
+	claims := []corev1.PersistentVolumeClaim{claim("web", "uploads"), bound, pending}
+	pods := []corev1.Pod{running, finished, otherNamespace}
+
+	orphans := orphanClaims(claims, pods)
+	if len(orphans) != 2 {
+		t.Fatalf("orphanClaims() returned %d claims, want 2: %+v", len(orphans), orphans)
+	}
+	if orphans[0].PVC != "new-uploads" || orphans[0].CapacityBytes != 1<<30 {
+		t.Errorf("orphanClaims()[0] = %s (%d bytes), want new-uploads (1Gi)", orphans[0].PVC, orphans[0].CapacityBytes)
+	}
+	if orphans[1].PVC != "old-uploads" || orphans[1].CapacityBytes != 10<<30 || orphans[1].Phase != "Bound" {
+		t.Errorf("orphanClaims()[1] = %+v, want old-uploads (10Gi, Bound)", orphans[1])
+	}
+}
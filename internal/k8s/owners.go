@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxOwnerDepth bounds how far we follow ownerReferences
+const maxOwnerDepth = 10
+
+// Workload identifies the top-level controller that owns a pod
+type Workload struct {
+	Kind string
+	Name string
+}
+
+// String formats the workload as Kind/Name
+func (w Workload) String() string {
+	if w.Kind == "" {
+		return ""
+	}
+	return w.Kind + "/" + w.Name
+}
+
+// ClaimKey identifies a PVC by namespace and name
+type ClaimKey struct {
+	Namespace string
+	Name      string
+}
+
+// ClaimConsumer describes the pods that mount a PVC and the workload that owns them
+type ClaimConsumer struct {
+	Pods     []string
+	Workload Workload
+	// Ephemeral is set when the PVC was generated for a generic ephemeral volume
+	Ephemeral bool
+}
+
+// ownerGetter fetches an owner object by kind and name within a namespace
+type ownerGetter func(namespace, kind, name string) (metav1.Object, error)
+
+// PodClaimNames returns the names of the PVCs a pod mounts, including the
+// claims generated for its generic ephemeral volumes
+func PodClaimNames(pod *corev1.Pod) []string {
+	var claims []string
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+		case volume.Ephemeral != nil:
+			// Generated claims are named <pod>-<volume>
+			claims = append(claims, pod.Name+"-"+volume.Name)
+		}
+	}
+	return claims
+}
+
+// ephemeralClaimNames returns the names of the PVCs generated for the generic
+// ephemeral volumes of a pod
+func ephemeralClaimNames(pod *corev1.Pod) map[string]bool {
+	names := make(map[string]bool)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Ephemeral != nil {
+			names[pod.Name+"-"+volume.Name] = true
+		}
+	}
+	return names
+}
+
+// resolveWorkload walks the controller ownerReferences of a pod up to its top-level owner.
+// Kinds we don't know how to fetch (e.g. operator custom resources) end the walk.
+// Results are memoized in cache, keyed by namespace/kind/name of the pod's direct owner.
+func resolveWorkload(pod *corev1.Pod, get ownerGetter, cache map[string]Workload) Workload {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return Workload{Kind: "Pod", Name: pod.Name}
+	}
+
+	cacheKey := pod.Namespace + "/" + ref.Kind + "/" + ref.Name
+	if workload, ok := cache[cacheKey]; ok {
+		return workload
+	}
+
+	workload := Workload{Kind: ref.Kind, Name: ref.Name}
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		owner, err := get(pod.Namespace, workload.Kind, workload.Name)
+		if err != nil || owner == nil {
+			break
+		}
+		next := metav1.GetControllerOf(owner)
+		if next == nil {
+			break
+		}
+		workload = Workload{Kind: next.Kind, Name: next.Name}
+	}
+
+	cache[cacheKey] = workload
+	return workload
+}
+
+// getOwner fetches the built-in controllers that can own pods or other controllers.
+// It returns nil for kinds it doesn't know, which ends the ownership walk.
+func (c *Client) getOwner(namespace, kind, name string) (metav1.Object, error) {
+	ctx := context.TODO()
+	switch kind {
+	case "ReplicaSet":
+		return c.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Deployment":
+		return c.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "StatefulSet":
+		return c.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "DaemonSet":
+		return c.Clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Job":
+		return c.Clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "CronJob":
+		return c.Clientset.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,90 @@
+package k8s
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// controllerRef builds a controller ownerReference
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func TestPodClaimNames(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0"},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-web-0"},
+				}},
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}}},
+				{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+
+	got := PodClaimNames(pod)
+	want := []string{"data-web-0", "web-0-scratch"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PodClaimNames() = %v, want %v", got, want)
+	}
+}
+
+func TestResolveWorkload(t *testing.T) {
+	objects := map[string]metav1.Object{
+		"ReplicaSet/api-7d9f": &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "api-7d9f", OwnerReferences: controllerRef("Deployment", "api"),
+		}},
+		"Deployment/api": &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api"}},
+		"StatefulSet/kafka-broker": &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "kafka-broker", OwnerReferences: controllerRef("Kafka", "kafka"),
+		}},
+	}
+	get := func(namespace, kind, name string) (metav1.Object, error) {
+		if obj, ok := objects[kind+"/"+name]; ok {
+			return obj, nil
+		}
+		return nil, fmt.Errorf("%s %q not found", kind, name)
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want Workload
+	}{
+		{
+			name: "deployment",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-7d9f-x", OwnerReferences: controllerRef("ReplicaSet", "api-7d9f")}},
+			want: Workload{Kind: "Deployment", Name: "api"},
+		},
+		{
+			name: "operator custom resource",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-broker-0", OwnerReferences: controllerRef("StatefulSet", "kafka-broker")}},
+			want: Workload{Kind: "Kafka", Name: "kafka"},
+		},
+		{
+			name: "missing owner",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "job-x", OwnerReferences: controllerRef("Job", "gone")}},
+			want: Workload{Kind: "Job", Name: "gone"},
+		},
+		{
+			name: "bare pod",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug"}},
+			want: Workload{Kind: "Pod", Name: "debug"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := resolveWorkload(tt.pod, get, make(map[string]Workload))
+		if got != tt.want {
+			t.Errorf("resolveWorkload(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// topologyLabels are the PV labels that pin a volume to a zone or region
+var topologyLabels = []string{
+	corev1.LabelTopologyZone,
+	corev1.LabelTopologyRegion,
+	corev1.LabelFailureDomainBetaZone,
+	corev1.LabelFailureDomainBetaRegion,
+}
+
+// volumeAffinity returns the node affinity needed to reach the volume bound to a PVC.
+// It returns nil when the PVC isn't bound yet or its volume is reachable from any node.
+func (c *Client) volumeAffinity(namespace, pvcName string) (*corev1.Affinity, error) {
+	claim, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting PVC: %v", err)
+	}
+
+	// Unbound claims (e.g. WaitForFirstConsumer) are bound wherever the monitor pod lands
+	if claim.Spec.VolumeName == "" {
+		return nil, nil
+	}
+
+	pv, err := c.Clientset.CoreV1().PersistentVolumes().Get(context.TODO(), claim.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting PersistentVolume: %v", err)
+	}
+
+	return pvAffinity(pv), nil
+}
+
+// pvAffinity builds a pod affinity from the PV's node affinity, or from its
+// topology labels when it has none
+func pvAffinity(pv *corev1.PersistentVolume) *corev1.Affinity {
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		return &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: pv.Spec.NodeAffinity.Required.DeepCopy(),
+			},
+		}
+	}
+
+	var expressions []corev1.NodeSelectorRequirement
+	for _, label := range topologyLabels {
+		if value, ok := pv.Labels[label]; ok {
+			expressions = append(expressions, corev1.NodeSelectorRequirement{
+				Key:      label,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{value},
+			})
+		}
+	}
+	if len(expressions) == 0 {
+		return nil
+	}
+
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: expressions}},
+			},
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPVAffinity(t *testing.T) {
+	localPV := &corev1.PersistentVolume{
+		Spec: corev1.PersistentVolumeSpec{
+			NodeAffinity: &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"worker-1"},
+					}},
+				}}},
+			},
+		},
+	}
+	zonalPV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{corev1.LabelTopologyZone: "eu-west-1a"}},
+	}
+	networkPV := &corev1.PersistentVolume{}
+
+	affinity := pvAffinity(localPV)
+	if affinity == nil {
+		t.Fatalf("pvAffinity(local) = nil, want node affinity")
+	}
+	term := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0]
+	if term.MatchExpressions[0].Values[0] != "worker-1" {
+		t.Errorf("pvAffinity(local) = %v, want hostname worker-1", term)
+	}
+
+	affinity = pvAffinity(zonalPV)
+	if affinity == nil {
+		t.Fatalf("pvAffinity(zonal) = nil, want zone affinity")
+	}
+	expr := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]
+	if expr.Key != corev1.LabelTopologyZone || expr.Values[0] != "eu-west-1a" {
+		t.Errorf("pvAffinity(zonal) = %v, want zone eu-west-1a", expr)
+	}
+
+	if affinity := pvAffinity(networkPV); affinity != nil {
+		t.Errorf("pvAffinity(network) = %v, want nil", affinity)
+	}
+}
@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// PodSettings configures the pods pvcusage creates on the cluster
+type PodSettings struct {
+	Image              string              `json:"image"`
+	ImagePullSecrets   []string            `json:"imagePullSecrets,omitempty"`
+	Tolerations        []corev1.Toleration `json:"tolerations,omitempty"`
+	PriorityClassName  string              `json:"priorityClassName,omitempty"`
+	ServiceAccountName string              `json:"serviceAccountName,omitempty"`
+	CPURequest         string              `json:"cpuRequest"`
+	CPULimit           string              `json:"cpuLimit"`
+	MemoryRequest      string              `json:"memoryRequest"`
+	MemoryLimit        string              `json:"memoryLimit"`
+	RunAsNonRoot       bool                `json:"runAsNonRoot"`
+	RunAsUser          int64               `json:"runAsUser,omitempty"`
+	// SeccompProfile is RuntimeDefault, Unconfined or Localhost/<path>
+	SeccompProfile string `json:"seccompProfile"`
+	// ActiveDeadlineSeconds stops the pod even if pvcusage never deletes it, 0 disables it
+	ActiveDeadlineSeconds int64 `json:"activeDeadlineSeconds"`
+}
+
+// DefaultPodSettings returns settings that comply with the "restricted" Pod Security Standard
+func DefaultPodSettings() PodSettings {
+	return PodSettings{
+		Image:          "nicolaka/netshoot", // Container with network and IO diagnostic tools
+		CPURequest:     "50m",
+		CPULimit:       "100m",
+		MemoryRequest:  "64Mi",
+		MemoryLimit:    "128Mi",
+		RunAsNonRoot:   true,
+		RunAsUser:      65534, // nobody, as the default image runs as root
+		SeccompProfile: string(corev1.SeccompProfileTypeRuntimeDefault),
+		// Monitoring sessions rarely last this long, leaked pods stop afterwards
+		ActiveDeadlineSeconds: 6 * 60 * 60,
+	}
+}
+
+// LoadPodSettings reads pod settings from a YAML file on top of base, e.g. the
+// defaults or a profile's settings. Fields the file doesn't set keep their base value.
+func LoadPodSettings(path string, base PodSettings) (PodSettings, error) {
+	settings := base
+	// Decoding reuses the backing arrays of slices, which belong to base
+	settings.ImagePullSecrets = append([]string(nil), base.ImagePullSecrets...)
+	settings.Tolerations = append([]corev1.Toleration(nil), base.Tolerations...)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return settings, fmt.Errorf("error reading pod settings: %v", err)
+	}
+	if err := yaml.UnmarshalStrict(data, &settings); err != nil {
+		return settings, fmt.Errorf("error parsing pod settings %s: %v", path, err)
+	}
+	return settings, nil
+}
+
+// Validate checks the resource quantities and seccomp profile
+func (s PodSettings) Validate() error {
+	if _, err := s.resources(); err != nil {
+		return err
+	}
+	_, err := s.seccompProfile()
+	return err
+}
+
+// ParseToleration parses a toleration written as key[=value]:effect, or
+// key:effect with no value to tolerate any value of the key
+func ParseToleration(s string) (corev1.Toleration, error) {
+	keyValue, effect, ok := strings.Cut(s, ":")
+	if !ok || keyValue == "" {
+		return corev1.Toleration{}, fmt.Errorf("invalid toleration %q (expected key[=value]:effect)", s)
+	}
+
+	toleration := corev1.Toleration{Effect: corev1.TaintEffect(effect)}
+	switch toleration.Effect {
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute, "":
+	default:
+		return corev1.Toleration{}, fmt.Errorf("invalid toleration effect %q", effect)
+	}
+
+	if key, value, hasValue := strings.Cut(keyValue, "="); hasValue {
+		toleration.Key = key
+		toleration.Operator = corev1.TolerationOpEqual
+		toleration.Value = value
+	} else {
+		toleration.Key = keyValue
+		toleration.Operator = corev1.TolerationOpExists
+	}
+	return toleration, nil
+}
+
+// Apply sets the configured fields on a pod spec and its container
+func (s PodSettings) Apply(spec *corev1.PodSpec, container *corev1.Container) error {
+	resources, err := s.resources()
+	if err != nil {
+		return err
+	}
+
+	seccomp, err := s.seccompProfile()
+	if err != nil {
+		return err
+	}
+
+	container.Image = s.Image
+	container.Resources = resources
+	container.SecurityContext = &corev1.SecurityContext{
+		Privileged:               boolPtr(false),
+		AllowPrivilegeEscalation: boolPtr(false),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+
+	spec.SecurityContext = &corev1.PodSecurityContext{
+		RunAsNonRoot:   boolPtr(s.RunAsNonRoot),
+		SeccompProfile: seccomp,
+	}
+	if s.RunAsUser != 0 {
+		spec.SecurityContext.RunAsUser = int64Ptr(s.RunAsUser)
+	}
+
+	for _, secret := range s.ImagePullSecrets {
+		spec.ImagePullSecrets = append(spec.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+	if s.ActiveDeadlineSeconds > 0 {
+		spec.ActiveDeadlineSeconds = int64Ptr(s.ActiveDeadlineSeconds)
+	}
+	spec.Tolerations = s.Tolerations
+	spec.PriorityClassName = s.PriorityClassName
+	spec.ServiceAccountName = s.ServiceAccountName
+	// The pods never talk to the API server
+	spec.AutomountServiceAccountToken = boolPtr(false)
+
+	return nil
+}
+
+// resources parses the configured requests and limits
+func (s PodSettings) resources() (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{
+		Limits:   corev1.ResourceList{},
+		Requests: corev1.ResourceList{},
+	}
+	for _, r := range []struct {
+		list  corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+	}{
+		{requirements.Requests, corev1.ResourceCPU, s.CPURequest},
+		{requirements.Limits, corev1.ResourceCPU, s.CPULimit},
+		{requirements.Requests, corev1.ResourceMemory, s.MemoryRequest},
+		{requirements.Limits, corev1.ResourceMemory, s.MemoryLimit},
+	} {
+		if r.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(r.value)
+		if err != nil {
+			return requirements, fmt.Errorf("invalid %s quantity %q: %v", r.name, r.value, err)
+		}
+		r.list[r.name] = quantity
+	}
+	return requirements, nil
+}
+
+// seccompProfile converts the configured profile name into a SeccompProfile
+func (s PodSettings) seccompProfile() (*corev1.SeccompProfile, error) {
+	switch {
+	case s.SeccompProfile == "":
+		return nil, nil
+	case s.SeccompProfile == string(corev1.SeccompProfileTypeRuntimeDefault):
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}, nil
+	case s.SeccompProfile == string(corev1.SeccompProfileTypeUnconfined):
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}, nil
+	case strings.HasPrefix(s.SeccompProfile, "Localhost/"):
+		path := strings.TrimPrefix(s.SeccompProfile, "Localhost/")
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &path}, nil
+	default:
+		return nil, fmt.Errorf("invalid seccomp profile %q (expected RuntimeDefault, Unconfined or Localhost/<path>)", s.SeccompProfile)
+	}
+}
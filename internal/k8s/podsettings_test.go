@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseToleration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    corev1.Toleration
+		wantErr bool
+	}{
+		{"dedicated=storage:NoSchedule", corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "storage", Effect: corev1.TaintEffectNoSchedule}, false},
+		{"node-role.kubernetes.io/control-plane:NoSchedule", corev1.Toleration{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}, false},
+		{"gpu:", corev1.Toleration{Key: "gpu", Operator: corev1.TolerationOpExists}, false},
+		{"gpu", corev1.Toleration{}, true},
+		{"gpu:Sometimes", corev1.Toleration{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseToleration(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseToleration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseToleration(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultPodSettingsRestricted(t *testing.T) {
+	var spec corev1.PodSpec
+	var container corev1.Container
+	if err := DefaultPodSettings().Apply(&spec, &container); err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+
+	// The checks of the "restricted" Pod Security Standard that apply to the monitor pod
+	sc := container.SecurityContext
+	if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		t.Errorf("allowPrivilegeEscalation must be false")
+	}
+	if sc.Capabilities == nil || len(sc.Capabilities.Drop) != 1 || sc.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("capabilities must drop ALL, got %v", sc.Capabilities)
+	}
+	if spec.SecurityContext.RunAsNonRoot == nil || !*spec.SecurityContext.RunAsNonRoot {
+		t.Errorf("runAsNonRoot must be true")
+	}
+	if spec.SecurityContext.RunAsUser == nil || *spec.SecurityContext.RunAsUser == 0 {
+		t.Errorf("runAsUser must be non-zero")
+	}
+	if spec.SecurityContext.SeccompProfile == nil || spec.SecurityContext.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("seccompProfile must be RuntimeDefault")
+	}
+}
+
+func TestLoadPodSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pod.yaml")
+	config := `image: registry.local/netshoot:v0.13
+imagePullSecrets: [registry-local]
+tolerations:
+- key: dedicated
+  operator: Equal
+  value: storage
+  effect: NoSchedule
+memoryLimit: 256Mi
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadPodSettings(path, DefaultPodSettings())
+	if err != nil {
+		t.Fatalf("LoadPodSettings() unexpected error: %v", err)
+	}
+	if settings.Image != "registry.local/netshoot:v0.13" || settings.MemoryLimit != "256Mi" {
+		t.Errorf("LoadPodSettings() = %+v", settings)
+	}
+	if len(settings.Tolerations) != 1 || settings.Tolerations[0].Value != "storage" {
+		t.Errorf("LoadPodSettings() tolerations = %v", settings.Tolerations)
+	}
+	// Unset fields keep their defaults
+	if settings.CPULimit != DefaultPodSettings().CPULimit || !settings.RunAsNonRoot {
+		t.Errorf("LoadPodSettings() lost defaults: %+v", settings)
+	}
+
+	// The file is laid over the base settings, e.g. those of a profile
+	base := DefaultPodSettings()
+	base.PriorityClassName = "system-cluster-critical"
+	base.ImagePullSecrets = []string{"profile-secret"}
+	settings, err = LoadPodSettings(path, base)
+	if err != nil {
+		t.Fatalf("LoadPodSettings() unexpected error: %v", err)
+	}
+	if settings.PriorityClassName != "system-cluster-critical" || settings.Image != "registry.local/netshoot:v0.13" {
+		t.Errorf("LoadPodSettings() over a profile = %+v", settings)
+	}
+	if base.ImagePullSecrets[0] != "profile-secret" {
+		t.Errorf("LoadPodSettings() modified the base pull secrets: %v", base.ImagePullSecrets)
+	}
+
+	if err := os.WriteFile(path, []byte("imagee: typo\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPodSettings(path, DefaultPodSettings()); err == nil {
+		t.Errorf("LoadPodSettings() expected error for unknown field")
+	}
+}
@@ -0,0 +1,36 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetNodeDiskPressure returns the nodes matching nodeSelector, each with
+// whether its DiskPressure condition is true
+func (c *Client) GetNodeDiskPressure(nodeSelector string) (map[string]bool, error) {
+	nodeList, err := c.Clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: nodeSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %v", err)
+	}
+
+	pressure := make(map[string]bool, len(nodeList.Items))
+	for i := range nodeList.Items {
+		pressure[nodeList.Items[i].Name] = hasDiskPressure(&nodeList.Items[i])
+	}
+	return pressure, nil
+}
+
+// hasDiskPressure reports whether the node has the DiskPressure condition
+func hasDiskPressure(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeDiskPressure {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestHasDiskPressure(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []corev1.NodeCondition
+		want       bool
+	}{
+		{"no conditions", nil, false},
+		{"pressure", []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+		}, true},
+		{"no pressure", []corev1.NodeCondition{
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+		}, false},
+		{"unknown", []corev1.NodeCondition{
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionUnknown},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		node := &corev1.Node{Status: corev1.NodeStatus{Conditions: tt.conditions}}
+		if got := hasDiskPressure(node); got != tt.want {
+			t.Errorf("%s: hasDiskPressure() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// storageClassQuotaSuffix ends the per-StorageClass quota resource names,
+// e.g. gold.storageclass.storage.k8s.io/requests.storage
+const storageClassQuotaSuffix = ".storageclass.storage.k8s.io/"
+
+// StorageQuota is one storage resource of a ResourceQuota
+type StorageQuota struct {
+	Namespace    string `json:"namespace"`
+	Quota        string `json:"quota"`
+	Resource     string `json:"resource"`               // requests.storage or persistentvolumeclaims
+	StorageClass string `json:"storageClass,omitempty"` // set for per-StorageClass quotas
+	Hard         int64  `json:"hard"`                   // bytes, or a count of PVCs
+	Used         int64  `json:"used"`
+}
+
+// GetStorageQuotas returns the storage resources of the ResourceQuotas, and
+// the smallest maximum PVC size of the LimitRanges of each namespace. An
+// empty namespace searches all namespaces.
+func (c *Client) GetStorageQuotas(namespace string) ([]StorageQuota, map[string]int64, error) {
+	quotaList, err := c.Clientset.CoreV1().ResourceQuotas(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing ResourceQuotas: %v", err)
+	}
+	limitList, err := c.Clientset.CoreV1().LimitRanges(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing LimitRanges: %v", err)
+	}
+	return storageQuotas(quotaList.Items), maxClaimSizes(limitList.Items), nil
+}
+
+// storageQuotas extracts the storage resources of quotas, ordered by
+// namespace, quota and resource
+func storageQuotas(quotas []corev1.ResourceQuota) []StorageQuota {
+	var result []StorageQuota
+	for _, quota := range quotas {
+		for name, hard := range quota.Status.Hard {
+			resource, storageClass := string(name), ""
+			if class, rest, ok := strings.Cut(resource, storageClassQuotaSuffix); ok {
+				resource, storageClass = rest, class
+			}
+			if resource != string(corev1.ResourceRequestsStorage) && resource != string(corev1.ResourcePersistentVolumeClaims) {
+				continue
+			}
+			used := quota.Status.Used[name]
+			result = append(result, StorageQuota{
+				Namespace:    quota.Namespace,
+				Quota:        quota.Name,
+				Resource:     resource,
+				StorageClass: storageClass,
+				Hard:         hard.Value(),
+				Used:         used.Value(),
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Quota != b.Quota {
+			return a.Quota < b.Quota
+		}
+		if a.StorageClass != b.StorageClass {
+			return a.StorageClass < b.StorageClass
+		}
+		return a.Resource > b.Resource // requests.storage first
+	})
+	return result
+}
+
+// maxClaimSizes returns the smallest PersistentVolumeClaim max storage of the
+// LimitRanges of each namespace
+func maxClaimSizes(limitRanges []corev1.LimitRange) map[string]int64 {
+	maxSizes := make(map[string]int64)
+	for _, limitRange := range limitRanges {
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != corev1.LimitTypePersistentVolumeClaim {
+				continue
+			}
+			max, ok := item.Max[corev1.ResourceStorage]
+			if !ok {
+				continue
+			}
+			if current, ok := maxSizes[limitRange.Namespace]; !ok || max.Value() < current {
+				maxSizes[limitRange.Namespace] = max.Value()
+			}
+		}
+	}
+	return maxSizes
+}
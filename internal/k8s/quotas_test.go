@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStorageQuotas(t *testing.T) {
+	quota := corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "kafka", Name: "storage"}}
+	quota.Status.Hard = corev1.ResourceList{
+		"requests.storage":                                  resource.MustParse("100Gi"),
+		"persistentvolumeclaims":                            resource.MustParse("5"),
+		"gold.storageclass.storage.k8s.io/requests.storage": resource.MustParse("20Gi"),
+		"requests.cpu":                                      resource.MustParse("4"),
+	}
+	quota.Status.Used = corev1.ResourceList{
+		"requests.storage":       resource.MustParse("60Gi"),
+		"persistentvolumeclaims": resource.MustParse("3"),
+		"requests.cpu":           resource.MustParse("1"),
+	}
+
+	expected := []StorageQuota{
+		{Namespace: "kafka", Quota: "storage", Resource: "requests.storage", Hard: 100 << 30, Used: 60 << 30},
+		{Namespace: "kafka", Quota: "storage", Resource: "persistentvolumeclaims", Hard: 5, Used: 3},
+		{Namespace: "kafka", Quota: "storage", Resource: "requests.storage", StorageClass: "gold", Hard: 20 << 30},
+	}
+	if got := storageQuotas([]corev1.ResourceQuota{quota}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("storageQuotas() =\n%+v\nwant\n%+v", got, expected)
+	}
+}
+
+func TestMaxClaimSizes(t *testing.T) {
+	limitRange := func(namespace string, limitType corev1.LimitType, max string) corev1.LimitRange {
+		return corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+				{Type: limitType, Max: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(max)}},
+			}},
+		}
+	}
+
+	limitRanges := []corev1.LimitRange{
+		limitRange("kafka", corev1.LimitTypePersistentVolumeClaim, "50Gi"),
+		limitRange("kafka", corev1.LimitTypePersistentVolumeClaim, "20Gi"),
+		limitRange("web", corev1.LimitTypeContainer, "1Gi"),
+	}
+	expected := map[string]int64{"kafka": 20 << 30}
+	if got := maxClaimSizes(limitRanges); !reflect.DeepEqual(got, expected) {
+		t.Errorf("maxClaimSizes() = %v, want %v", got, expected)
+	}
+}
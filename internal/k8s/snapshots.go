@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Resources of the snapshot.storage.k8s.io API, served by the CSI snapshot controller
+var (
+	volumeSnapshotsResource        = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+	volumeSnapshotContentsResource = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotcontents"}
+)
+
+// Snapshot is a VolumeSnapshot, or a retained VolumeSnapshotContent whose
+// VolumeSnapshot was deleted
+type Snapshot struct {
+	Namespace        string    `json:"namespace"`
+	Name             string    `json:"name,omitempty"` // empty for retained contents
+	SourcePVC        string    `json:"sourcePVC,omitempty"`
+	Content          string    `json:"content,omitempty"`
+	SnapshotClass    string    `json:"snapshotClass,omitempty"`
+	DeletionPolicy   string    `json:"deletionPolicy,omitempty"`
+	RestoreSizeBytes int64     `json:"restoreSizeBytes"`
+	ReadyToUse       bool      `json:"readyToUse"`
+	CreatedAt        time.Time `json:"createdAt"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// SnapshotStats summarizes the snapshots of a PVC
+type SnapshotStats struct {
+	Count            int       `json:"count"`
+	Ready            int       `json:"ready"`
+	RestoreSizeBytes int64     `json:"restoreSizeBytes"`
+	LatestAt         time.Time `json:"latestAt"`
+}
+
+// GetSnapshots returns the VolumeSnapshots of a namespace and the retained
+// contents left by deleted ones, ordered by namespace, source PVC and creation.
+// An empty namespace searches all namespaces.
+func (c *Client) GetSnapshots(namespace string) ([]Snapshot, error) {
+	snapshotList, err := c.Dynamic.Resource(volumeSnapshotsResource).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("the snapshot.storage.k8s.io/v1 API is not available, is the CSI snapshot controller installed?")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing VolumeSnapshots: %v", err)
+	}
+
+	// Contents are cluster-scoped, users limited to a namespace may not list them
+	contentList, err := c.Dynamic.Resource(volumeSnapshotContentsResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Warning: could not list VolumeSnapshotContents, sizes may be missing: %v", err)
+		contentList = &unstructured.UnstructuredList{}
+	}
+
+	return parseSnapshots(snapshotList.Items, contentList.Items, namespace), nil
+}
+
+// parseSnapshots joins VolumeSnapshots with their contents. The restore size
+// and readiness fall back to the content's when the snapshot has none yet.
+func parseSnapshots(snapshots, contents []unstructured.Unstructured, namespace string) []Snapshot {
+	contentByName := make(map[string]*unstructured.Unstructured)
+	for i := range contents {
+		contentByName[contents[i].GetName()] = &contents[i]
+	}
+
+	var result []Snapshot
+	bound := make(map[string]bool)
+	for i := range snapshots {
+		object := snapshots[i].Object
+		s := Snapshot{
+			Namespace: snapshots[i].GetNamespace(),
+			Name:      snapshots[i].GetName(),
+			CreatedAt: snapshots[i].GetCreationTimestamp().Time,
+		}
+		s.SourcePVC, _, _ = unstructured.NestedString(object, "spec", "source", "persistentVolumeClaimName")
+		s.SnapshotClass, _, _ = unstructured.NestedString(object, "spec", "volumeSnapshotClassName")
+		s.Content, _, _ = unstructured.NestedString(object, "status", "boundVolumeSnapshotContentName")
+		s.ReadyToUse, _, _ = unstructured.NestedBool(object, "status", "readyToUse")
+		s.Error, _, _ = unstructured.NestedString(object, "status", "error", "message")
+		if created, ok, _ := unstructured.NestedString(object, "status", "creationTime"); ok {
+			if t, err := time.Parse(time.RFC3339, created); err == nil {
+				s.CreatedAt = t
+			}
+		}
+		if size, ok, _ := unstructured.NestedString(object, "status", "restoreSize"); ok {
+			if quantity, err := resource.ParseQuantity(size); err == nil {
+				s.RestoreSizeBytes = quantity.Value()
+			}
+		}
+
+		if content, ok := contentByName[s.Content]; ok {
+			bound[s.Content] = true
+			s.DeletionPolicy, _, _ = unstructured.NestedString(content.Object, "spec", "deletionPolicy")
+			if s.RestoreSizeBytes == 0 {
+				s.RestoreSizeBytes, _, _ = unstructured.NestedInt64(content.Object, "status", "restoreSize")
+			}
+			if !s.ReadyToUse {
+				s.ReadyToUse, _, _ = unstructured.NestedBool(content.Object, "status", "readyToUse")
+			}
+		}
+		result = append(result, s)
+	}
+
+	// Retained contents keep their storage after the VolumeSnapshot is deleted
+	for i := range contents {
+		content := &contents[i]
+		if bound[content.GetName()] {
+			continue
+		}
+		refNamespace, _, _ := unstructured.NestedString(content.Object, "spec", "volumeSnapshotRef", "namespace")
+		if namespace != "" && refNamespace != namespace {
+			continue
+		}
+		s := Snapshot{
+			Namespace: refNamespace,
+			Content:   content.GetName(),
+			CreatedAt: content.GetCreationTimestamp().Time,
+		}
+		s.SnapshotClass, _, _ = unstructured.NestedString(content.Object, "spec", "volumeSnapshotClassName")
+		s.DeletionPolicy, _, _ = unstructured.NestedString(content.Object, "spec", "deletionPolicy")
+		s.RestoreSizeBytes, _, _ = unstructured.NestedInt64(content.Object, "status", "restoreSize")
+		s.ReadyToUse, _, _ = unstructured.NestedBool(content.Object, "status", "readyToUse")
+		result = append(result, s)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		if result[i].SourcePVC != result[j].SourcePVC {
+			return result[i].SourcePVC < result[j].SourcePVC
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// SnapshotStatsByClaim counts the snapshots of each source PVC
+func SnapshotStatsByClaim(snapshots []Snapshot) map[ClaimKey]*SnapshotStats {
+	stats := make(map[ClaimKey]*SnapshotStats)
+	for _, s := range snapshots {
+		if s.SourcePVC == "" {
+			continue
+		}
+		key := ClaimKey{Namespace: s.Namespace, Name: s.SourcePVC}
+		st, ok := stats[key]
+		if !ok {
+			st = &SnapshotStats{}
+			stats[key] = st
+		}
+		st.Count++
+		if s.ReadyToUse {
+			st.Ready++
+		}
+		st.RestoreSizeBytes += s.RestoreSizeBytes
+		if s.CreatedAt.After(st.LatestAt) {
+			st.LatestAt = s.CreatedAt
+		}
+	}
+	return stats
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseSnapshots(t *testing.T) {
+	snapshot := func(namespace, name, pvc, content string, status map[string]interface{}) unstructured.Unstructured {
+		status["boundVolumeSnapshotContentName"] = content
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"namespace": namespace, "name": name},
+			"spec": map[string]interface{}{
+				"source":                  map[string]interface{}{"persistentVolumeClaimName": pvc},
+				"volumeSnapshotClassName": "csi-snapclass",
+			},
+			"status": status,
+		}}
+	}
+	content := func(name, namespace string, size int64, ready bool) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"deletionPolicy":    "Retain",
+				"volumeSnapshotRef": map[string]interface{}{"namespace": namespace, "name": "deleted"},
+			},
+			"status": map[string]interface{}{"restoreSize": size, "readyToUse": ready},
+		}}
+	}
+
+	snapshots := []unstructured.Unstructured{
+		snapshot("db", "daily-2", "data", "content-2", map[string]interface{}{
+			"creationTime": "2025-04-02T00:00:00Z", "readyToUse": true, "restoreSize": "10Gi",
+		}),
+		snapshot("db", "daily-1", "data", "content-1", map[string]interface{}{
+			"creationTime": "2025-04-01T00:00:00Z",
+		}),
+	}
+	contents := []unstructured.Unstructured{
+		content("content-1", "db", 5<<30, true),
+		content("content-2", "db", 10<<30, true),
+		content("content-old", "db", 1<<30, false),
+		content("content-web", "web", 1<<30, true),
+	}
+
+	result := parseSnapshots(snapshots, contents, "db")
+	if len(result) != 3 {
+		t.Fatalf("parseSnapshots() returned %d snapshots, want 3: %+v", len(result), result)
+	}
+	if result[0].Content != "content-old" || result[0].Name != "" || result[0].RestoreSizeBytes != 1<<30 {
+		t.Errorf("parseSnapshots()[0] = %+v, want the retained content-old", result[0])
+	}
+	first := result[1]
+	if first.Name != "daily-1" || first.SourcePVC != "data" || first.RestoreSizeBytes != 5<<30 ||
+		!first.ReadyToUse || first.DeletionPolicy != "Retain" || first.SnapshotClass != "csi-snapclass" {
+		t.Errorf("parseSnapshots()[1] = %+v, want daily-1 with the size and readiness of content-1", first)
+	}
+	if result[2].Name != "daily-2" || result[2].RestoreSizeBytes != 10<<30 {
+		t.Errorf("parseSnapshots()[2] = %+v, want daily-2 (10Gi)", result[2])
+	}
+
+	stats := SnapshotStatsByClaim(result)
+	expected := SnapshotStats{Count: 2, Ready: 2, RestoreSizeBytes: 15 << 30, LatestAt: time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)}
+	if got := stats[ClaimKey{Namespace: "db", Name: "data"}]; got == nil || *got != expected {
+		t.Errorf("SnapshotStatsByClaim() = %+v, want %+v", got, expected)
+	}
+	if len(stats) != 1 {
+		t.Errorf("SnapshotStatsByClaim() returned %d claims, want 1", len(stats))
+	}
+}
@@ -2,16 +2,40 @@ package k8s
 
 // Summary represents the node stats summary structure.
 type Summary struct {
-	Pods []Pod `json:"pods"`
+	Node NodeStats `json:"node"`
+	Pods []Pod     `json:"pods"`
 }
 
-// Pod contains volume usage stats.
+// NodeStats contains the filesystem stats of the node.
+type NodeStats struct {
+	NodeName string   `json:"nodeName"`
+	Fs       *FsStats `json:"fs"`
+	Runtime  *struct {
+		ImageFs *FsStats `json:"imageFs"`
+	} `json:"runtime"`
+}
+
+// FsStats represents the usage of a filesystem.
+type FsStats struct {
+	CapacityBytes  int64 `json:"capacityBytes"`
+	UsedBytes      int64 `json:"usedBytes"`
+	AvailableBytes int64 `json:"availableBytes"`
+}
+
+// Pod contains volume and ephemeral storage usage stats.
 type Pod struct {
+	PodRef struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"podRef"`
 	Volumes []Volume `json:"volume"`
+	// EphemeralStorage covers the container writable layers, logs and local volumes
+	EphemeralStorage *FsStats `json:"ephemeral-storage"`
 }
 
 // Volume represents a volume in the stats summary.
 type Volume struct {
+	Name   string `json:"name"`
 	PVCRef *struct {
 		Namespace string `json:"namespace"`
 		Name      string `json:"name"`
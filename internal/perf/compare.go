@@ -0,0 +1,31 @@
+package perf
+
+import "sort"
+
+// outlierThreshold is how far from the median (as a fraction of it) a value must be to stand out
+const outlierThreshold = 0.5
+
+// Outliers reports which values deviate from the median of the group by more
+// than half of it. Groups of fewer than 3 values have no outliers.
+func Outliers(values []float64) []bool {
+	outliers := make([]bool, len(values))
+	if len(values) < 3 {
+		return outliers
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	if median == 0 {
+		return outliers
+	}
+
+	for i, v := range values {
+		deviation := (v - median) / median
+		outliers[i] = deviation > outlierThreshold || deviation < -outlierThreshold
+	}
+	return outliers
+}
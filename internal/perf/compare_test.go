@@ -0,0 +1,24 @@
+package perf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutliers(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   []bool
+	}{
+		{[]float64{100, 110, 95, 400, 105}, []bool{false, false, false, true, false}},
+		{[]float64{10, 100, 105, 98}, []bool{true, false, false, false}},
+		{[]float64{1, 100}, []bool{false, false}},
+		{[]float64{0, 0, 5}, []bool{false, false, false}},
+	}
+
+	for _, tt := range tests {
+		if got := Outliers(tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Outliers(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
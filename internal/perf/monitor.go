@@ -2,6 +2,7 @@ package perf
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
@@ -18,17 +19,17 @@ import (
 
 // Metrics represents performance metrics for a PVC
 type Metrics struct {
-	Timestamp         time.Time
-	IOPS              int64
-	Throughput        int64 // bytes per second
-	Latency           int64 // milliseconds
-	DiskUtilPct       float64
-	DiskSpace         int64
-	DiskSpaceUsed     int64
-	DiskSpacePct      float64
-	ReadOnly          bool
-	SystemLoad        float64
-	CPUWaitPercentage float64
+	Timestamp         time.Time `json:"timestamp"`
+	IOPS              int64     `json:"iops"`
+	Throughput        int64     `json:"throughput"` // bytes per second
+	Latency           int64     `json:"latency"`    // milliseconds
+	DiskUtilPct       float64   `json:"diskUtilPct"`
+	DiskSpace         int64     `json:"diskSpace"`
+	DiskSpaceUsed     int64     `json:"diskSpaceUsed"`
+	DiskSpacePct      float64   `json:"diskSpacePct"`
+	ReadOnly          bool      `json:"readOnly"`
+	SystemLoad        float64   `json:"systemLoad"`
+	CPUWaitPercentage float64   `json:"cpuWaitPercentage"`
 }
 
 // Monitor watches the performance of a PVC
@@ -38,16 +39,35 @@ type Monitor struct {
 	podName     string
 	pvcName     string
 	perfPod     string
+	container   string // ephemeral monitor container in the consumer pod, if attached
 	stopChan    chan struct{}
 	metrics     *Metrics
 	mu          sync.RWMutex
 	ctx         context.Context
 	cancel      context.CancelFunc
-	useFallback bool // Flag to indicate if we're using fallback monitoring
+	useFallback bool      // Flag to indicate if we're using fallback monitoring
+	recorder    *Recorder // Optional recording of every sample
+	history     []Metrics // Most recent samples, oldest first
 }
 
-// StartMonitoring creates a performance pod and starts collecting metrics
-func StartMonitoring(client *k8s.Client, namespace, podName, pvcName string) (*Monitor, error) {
+// HistorySize is the number of recent samples kept for trend graphs
+const HistorySize = 30
+
+// StartMonitoring creates a performance pod and starts collecting metrics. With
+// ephemeral set, the monitor is injected into the consumer pod as an ephemeral
+// container instead, falling back to a separate pod when that isn't allowed.
+func StartMonitoring(client *k8s.Client, namespace, podName, pvcName string, settings k8s.PodSettings, ephemeral bool) (*Monitor, error) {
+	if ephemeral && podName != "" {
+		container, err := client.AttachPerformanceContainer(namespace, podName, pvcName, settings)
+		if err == nil {
+			return startEphemeralMonitoring(client, namespace, podName, pvcName, container)
+		}
+		if !errors.Is(err, k8s.ErrEphemeralUnsupported) {
+			return nil, err
+		}
+		log.Printf("WARNING: %v. Falling back to a separate performance pod.", err)
+	}
+
 	// Create a monitoring pod that accesses the same PVC
 	var perfPod string
 	var err error
@@ -55,7 +75,7 @@ func StartMonitoring(client *k8s.Client, namespace, podName, pvcName string) (*M
 
 	// Try up to 3 times to create the performance pod
 	for attempt := 1; attempt <= 3; attempt++ {
-		perfPod, err = client.CreatePerformancePod(namespace, podName, pvcName)
+		perfPod, err = client.CreatePerformancePod(namespace, podName, pvcName, settings)
 		if err == nil {
 			break // Pod created successfully
 		}
@@ -106,6 +126,60 @@ func StartMonitoring(client *k8s.Client, namespace, podName, pvcName string) (*M
 	return m, nil
 }
 
+// startEphemeralMonitoring collects metrics from a monitor container injected into the consumer pod
+func startEphemeralMonitoring(client *k8s.Client, namespace, podName, pvcName, container string) (*Monitor, error) {
+	log.Printf("Waiting for ephemeral container %s in pod %s to start...", container, podName)
+	useFallback := false
+	if err := waitForEphemeralContainer(client, namespace, podName, container); err != nil {
+		log.Printf("WARNING: Ephemeral container not running: %v. Using fallback monitoring.", err)
+		useFallback = true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Monitor{
+		client:      client,
+		namespace:   namespace,
+		podName:     podName,
+		pvcName:     pvcName,
+		perfPod:     podName,
+		container:   container,
+		stopChan:    make(chan struct{}),
+		metrics:     &Metrics{},
+		ctx:         ctx,
+		cancel:      cancel,
+		useFallback: useFallback,
+	}
+
+	go m.collectMetrics()
+
+	return m, nil
+}
+
+// waitForEphemeralContainer waits until the ephemeral container is running
+func waitForEphemeralContainer(client *k8s.Client, namespace, podName, container string) error {
+	for attempt := 0; attempt < 120; attempt++ {
+		pod, err := client.Clientset.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != container {
+				continue
+			}
+			if status.State.Running != nil {
+				return nil
+			}
+			if status.State.Terminated != nil {
+				return fmt.Errorf("container %s terminated: %s", container, status.State.Terminated.Reason)
+			}
+		}
+
+		time.Sleep(1 * time.Second)
+	}
+	return fmt.Errorf("timed out waiting for container %s", container)
+}
+
 // waitForPodReady waits until the pod is in the Running state
 func waitForPodReady(client *k8s.Client, namespace, podName string) error {
 	for {
@@ -128,8 +202,26 @@ func (m *Monitor) collectMetrics() {
 	ticker := time.NewTicker(1 * time.Second) // Collect every 1 second
 	defer ticker.Stop()
 
+	// Keep the monitor alive; it exits on its own once heartbeats stop
+	heartbeat := time.NewTicker(k8s.HeartbeatInterval)
+	defer heartbeat.Stop()
+
 	for {
 		select {
+		case <-heartbeat.C:
+			if m.useFallback {
+				continue
+			}
+			var err error
+			if m.container != "" {
+				err = m.client.SetEphemeralDeadline(m.namespace, m.perfPod, m.container, time.Now().Add(k8s.EphemeralLease))
+			} else {
+				err = m.client.Heartbeat(m.namespace, m.perfPod)
+			}
+			if err != nil {
+				log.Printf("Warning: %v", err)
+			}
+
 		case <-ticker.C:
 			metrics, err := m.getMetricsFromPod()
 			if err != nil {
@@ -139,8 +231,19 @@ func (m *Monitor) collectMetrics() {
 
 			m.mu.Lock()
 			m.metrics = metrics
+			m.history = append(m.history, *metrics)
+			if len(m.history) > HistorySize {
+				m.history = m.history[len(m.history)-HistorySize:]
+			}
+			recorder := m.recorder
 			m.mu.Unlock()
 
+			if recorder != nil {
+				if err := recorder.Write(*metrics); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+			}
+
 		case <-m.ctx.Done():
 			return
 		}
@@ -177,6 +280,7 @@ func (m *Monitor) getMetricsFromPod() (*Metrics, error) {
 	if !m.useFallback {
 		// Try to get real values from the pod logs
 		logs, err := m.client.Clientset.CoreV1().Pods(m.namespace).GetLogs(m.perfPod, &corev1.PodLogOptions{
+			Container: m.container,
 			TailLines: int64Ptr(100), // Get last 100 lines to make sure we capture the latest metrics
 		}).Do(context.TODO()).Raw()
 
@@ -312,6 +416,13 @@ func int64Ptr(i int64) *int64 {
 	return &i
 }
 
+// SetRecorder records every subsequent sample with r
+func (m *Monitor) SetRecorder(r *Recorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recorder = r
+}
+
 // GetLatestMetrics returns the most recent metrics
 func (m *Monitor) GetLatestMetrics() Metrics {
 	m.mu.RLock()
@@ -319,6 +430,13 @@ func (m *Monitor) GetLatestMetrics() Metrics {
 	return *m.metrics
 }
 
+// GetHistory returns the most recent samples, oldest first
+func (m *Monitor) GetHistory() []Metrics {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Metrics(nil), m.history...)
+}
+
 // Stop stops monitoring and cleans up resources
 func (m *Monitor) Stop() error {
 	m.cancel()
@@ -327,6 +445,16 @@ func (m *Monitor) Stop() error {
 	if m.useFallback {
 		return nil
 	}
+
+	// Ephemeral containers can't be removed, so move their deadline to now
+	if m.container != "" {
+		if err := m.client.SetEphemeralDeadline(m.namespace, m.perfPod, m.container, time.Now()); err != nil {
+			log.Printf("Warning: %v. Ephemeral container %s in pod %s will exit within %s", err, m.container, m.perfPod, k8s.EphemeralLease)
+			return nil
+		}
+		log.Printf("Ephemeral container %s in pod %s stopped", m.container, m.perfPod)
+		return nil
+	}
 	deletePolicy := metav1.DeletePropagationForeground
 
 	deleteOptions := metav1.DeleteOptions{
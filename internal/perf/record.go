@@ -0,0 +1,134 @@
+package perf
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Recorder writes metrics samples to a JSON Lines file
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewRecorder creates (or truncates) a recording file
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating recording: %v", err)
+	}
+	return &Recorder{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Write appends a sample to the recording
+func (r *Recorder) Write(metrics Metrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.encoder.Encode(metrics); err != nil {
+		return fmt.Errorf("error writing recording: %v", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the recording file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// ReadRecording reads every sample of a recording file
+func ReadRecording(path string) ([]Metrics, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording: %v", err)
+	}
+	defer file.Close()
+
+	var samples []Metrics
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var m Metrics
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid sample: %v", path, line, err)
+		}
+		samples = append(samples, m)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading recording: %v", err)
+	}
+	return samples, nil
+}
+
+// MetricSummary holds the statistics of one metric over a recording
+type MetricSummary struct {
+	Name  string
+	Unit  string // "bytes", "bytes/s", "ms", "%" or "" for plain numbers
+	Min   float64
+	Avg   float64
+	Max   float64
+	P95   float64
+	Count int
+}
+
+// summarizedMetrics lists the metrics included in a summary
+var summarizedMetrics = []struct {
+	name  string
+	unit  string
+	value func(Metrics) float64
+}{
+	{"IOPS", "", func(m Metrics) float64 { return float64(m.IOPS) }},
+	{"Throughput", "bytes/s", func(m Metrics) float64 { return float64(m.Throughput) }},
+	{"Latency", "ms", func(m Metrics) float64 { return float64(m.Latency) }},
+	{"Disk Util", "%", func(m Metrics) float64 { return m.DiskUtilPct }},
+	{"Disk Used", "bytes", func(m Metrics) float64 { return float64(m.DiskSpaceUsed) }},
+	{"Disk Used %", "%", func(m Metrics) float64 { return m.DiskSpacePct }},
+	{"System Load", "", func(m Metrics) float64 { return m.SystemLoad }},
+	{"I/O Wait", "%", func(m Metrics) float64 { return m.CPUWaitPercentage }},
+}
+
+// Summarize computes min/avg/max/p95 for each metric of the samples
+func Summarize(samples []Metrics) []MetricSummary {
+	summaries := make([]MetricSummary, 0, len(summarizedMetrics))
+	for _, metric := range summarizedMetrics {
+		values := make([]float64, len(samples))
+		for i, s := range samples {
+			values[i] = metric.value(s)
+		}
+		summary := MetricSummary{Name: metric.name, Unit: metric.unit, Count: len(values)}
+		if len(values) > 0 {
+			sort.Float64s(values)
+			sum := 0.0
+			for _, v := range values {
+				sum += v
+			}
+			summary.Min = values[0]
+			summary.Max = values[len(values)-1]
+			summary.Avg = sum / float64(len(values))
+			summary.P95 = percentile(values, 95)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
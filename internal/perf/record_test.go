@@ -0,0 +1,66 @@
+package perf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := recorder.Write(Metrics{Timestamp: start.Add(time.Duration(i) * time.Second), IOPS: int64(100 * (i + 1))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := ReadRecording(path)
+	if err != nil {
+		t.Fatalf("ReadRecording() unexpected error: %v", err)
+	}
+	if len(samples) != 3 || samples[2].IOPS != 300 || !samples[1].Timestamp.Equal(start.Add(time.Second)) {
+		t.Errorf("ReadRecording() = %+v", samples)
+	}
+
+	if err := os.WriteFile(path, []byte("{\"iops\": 1}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadRecording(path); err == nil {
+		t.Errorf("ReadRecording() expected error for invalid line")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []Metrics
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, Metrics{IOPS: int64(i * 10), DiskUtilPct: float64(i)})
+	}
+
+	summaries := Summarize(samples)
+	byName := make(map[string]MetricSummary)
+	for _, s := range summaries {
+		byName[s.Name] = s
+	}
+
+	iops := byName["IOPS"]
+	if iops.Min != 10 || iops.Max != 200 || iops.Avg != 105 || iops.P95 != 190 || iops.Count != 20 {
+		t.Errorf("IOPS summary = %+v", iops)
+	}
+	if util := byName["Disk Util"]; util.P95 != 19 || util.Unit != "%" {
+		t.Errorf("Disk Util summary = %+v", util)
+	}
+
+	if empty := Summarize(nil); len(empty) == 0 || empty[0].Count != 0 {
+		t.Errorf("Summarize(nil) = %+v", empty)
+	}
+}
@@ -0,0 +1,63 @@
+package pvc
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+// Capacity lines up the sizes of a PVC: requested, status.capacity, PV and filesystem
+type Capacity struct {
+	k8s.ClaimSize
+	FilesystemBytes int64    `json:"filesystemBytes"` // 0 when no pod mounts the PVC
+	Issues          []string `json:"issues,omitempty"`
+}
+
+// ReconcileCapacities adds the filesystem capacity reported by the kubelet to
+// each claim and notes pending resizes and sizes that differ from the request
+// by more than tolerance percent
+func ReconcileCapacities(sizes []k8s.ClaimSize, usages []Usage, tolerance float64) []Capacity {
+	filesystemBytes := make(map[k8s.ClaimKey]int64)
+	for _, u := range usages {
+		filesystemBytes[k8s.ClaimKey{Namespace: u.Namespace, Name: u.PVC}] = u.CapacityBytes
+	}
+
+	capacities := make([]Capacity, 0, len(sizes))
+	for _, size := range sizes {
+		c := Capacity{
+			ClaimSize:       size,
+			FilesystemBytes: filesystemBytes[k8s.ClaimKey{Namespace: size.Namespace, Name: size.PVC}],
+		}
+		if size.Resizing {
+			c.Issues = append(c.Issues, "volume resize in progress")
+		}
+		if size.FileSystemResizePending {
+			c.Issues = append(c.Issues, "filesystem resize pending until the pod restarts")
+		}
+		for _, other := range []struct {
+			name  string
+			bytes int64
+		}{
+			{"status capacity", size.StatusBytes},
+			{"PV capacity", size.VolumeBytes},
+			{"filesystem", c.FilesystemBytes},
+		} {
+			// Unknown sizes, e.g. of unbound or unmounted claims, can't disagree
+			if other.bytes == 0 || size.RequestedBytes == 0 {
+				continue
+			}
+			ratio := float64(other.bytes) / float64(size.RequestedBytes) * 100
+			if math.Abs(ratio-100) > tolerance {
+				c.Issues = append(c.Issues, fmt.Sprintf("%s is %.0f%% of the request", other.name, ratio))
+			}
+		}
+		capacities = append(capacities, c)
+	}
+	return capacities
+}
+
+// HasIssues reports whether any size disagrees or a resize is pending
+func (c Capacity) HasIssues() bool {
+	return len(c.Issues) > 0
+}
@@ -0,0 +1,53 @@
+package pvc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+func TestReconcileCapacities(t *testing.T) {
+	sizes := []k8s.ClaimSize{
+		{Namespace: "db", PVC: "healthy", RequestedBytes: 100, StatusBytes: 100, VolumeBytes: 100},
+		{Namespace: "db", PVC: "unbound", RequestedBytes: 100},
+		{Namespace: "kafka", PVC: "data-0", RequestedBytes: 200, StatusBytes: 100, VolumeBytes: 200, FileSystemResizePending: true},
+		{Namespace: "web", PVC: "rounded", RequestedBytes: 100, StatusBytes: 150, VolumeBytes: 150, Resizing: true},
+	}
+	usages := []Usage{
+		{Namespace: "db", PVC: "healthy", CapacityBytes: 97},
+		{Namespace: "kafka", PVC: "data-0", CapacityBytes: 98},
+	}
+
+	expected := map[string][]string{
+		"healthy": nil,
+		"unbound": nil,
+		"data-0": {
+			"filesystem resize pending until the pod restarts",
+			"status capacity is 50% of the request",
+			"filesystem is 49% of the request",
+		},
+		"rounded": {
+			"volume resize in progress",
+			"status capacity is 150% of the request",
+			"PV capacity is 150% of the request",
+		},
+	}
+
+	capacities := ReconcileCapacities(sizes, usages, 5)
+	if len(capacities) != len(sizes) {
+		t.Fatalf("ReconcileCapacities() returned %d claims, want %d", len(capacities), len(sizes))
+	}
+	for _, c := range capacities {
+		if !reflect.DeepEqual(c.Issues, expected[c.PVC]) {
+			t.Errorf("ReconcileCapacities() issues of %s = %q, want %q", c.PVC, c.Issues, expected[c.PVC])
+		}
+		if c.HasIssues() != (expected[c.PVC] != nil) {
+			t.Errorf("HasIssues() of %s = %v", c.PVC, c.HasIssues())
+		}
+	}
+	if capacities[0].FilesystemBytes != 97 || capacities[1].FilesystemBytes != 0 {
+		t.Errorf("ReconcileCapacities() filesystem sizes = %d, %d; want 97, 0",
+			capacities[0].FilesystemBytes, capacities[1].FilesystemBytes)
+	}
+}
@@ -0,0 +1,197 @@
+package pvc
+
+import (
+	"sort"
+	"time"
+)
+
+// Event types emitted when watching PVC usage
+const (
+	EventAdded            = "added"
+	EventRemoved          = "removed"
+	EventUsageChanged     = "usage_changed"
+	EventThresholdChanged = "threshold_changed"
+)
+
+// Threshold states of a PVC
+const (
+	StateOK       = "ok"
+	StateWarning  = "warning"
+	StateCritical = "critical"
+)
+
+// Thresholds are the usage percentages at which a PVC becomes warning or critical
+type Thresholds struct {
+	Warn float64 `json:"warn"`
+	Crit float64 `json:"crit"`
+}
+
+// State returns the threshold state of a usage percentage
+func (t Thresholds) State(percentage float64) string {
+	switch {
+	case percentage >= t.Crit:
+		return StateCritical
+	case percentage >= t.Warn:
+		return StateWarning
+	default:
+		return StateOK
+	}
+}
+
+// Event describes a meaningful change of a PVC between two refreshes
+type Event struct {
+	Time      time.Time   `json:"time"`
+	Type      string      `json:"type"`
+	Namespace string      `json:"namespace"`
+	PVC       string      `json:"pvc"`
+	Old       *EventValue `json:"old,omitempty"`
+	New       *EventValue `json:"new,omitempty"`
+	// MinChange and Filter are the settings the event was emitted with, so
+	// that readers of a log know which changes it leaves out
+	MinChange *float64 `json:"minChange,omitempty"`
+	Filter    string   `json:"filter,omitempty"`
+}
+
+// EventValue is the state of a PVC before or after an event
+type EventValue struct {
+	CapacityBytes  int64   `json:"capacityBytes"`
+	UsedBytes      int64   `json:"usedBytes"`
+	AvailableBytes int64   `json:"availableBytes"`
+	PercentageUsed float64 `json:"percentageUsed"`
+	State          string  `json:"state"`
+}
+
+// eventValue captures the event-relevant state of a usage
+func eventValue(u Usage, thresholds Thresholds) *EventValue {
+	return &EventValue{
+		CapacityBytes:  u.CapacityBytes,
+		UsedBytes:      u.UsedBytes,
+		AvailableBytes: u.AvailableBytes,
+		PercentageUsed: u.PercentageUsed,
+		State:          thresholds.State(u.PercentageUsed),
+	}
+}
+
+// DiffUsages compares two refreshes and returns the events between them, ordered
+// by namespace and PVC. Usage changes smaller than minChange percentage points
+// are ignored, unless they cross a threshold.
+func DiffUsages(previous, current []Usage, minChange float64, thresholds Thresholds, now time.Time) []Event {
+	key := func(u Usage) string { return u.Namespace + "/" + u.PVC }
+
+	before := make(map[string]Usage, len(previous))
+	for _, u := range previous {
+		before[key(u)] = u
+	}
+	after := make(map[string]Usage, len(current))
+	for _, u := range current {
+		after[key(u)] = u
+	}
+
+	var events []Event
+	newEvent := func(eventType string, u Usage) Event {
+		return Event{Time: now, Type: eventType, Namespace: u.Namespace, PVC: u.PVC}
+	}
+
+	for k, u := range after {
+		old, existed := before[k]
+		if !existed {
+			e := newEvent(EventAdded, u)
+			e.New = eventValue(u, thresholds)
+			events = append(events, e)
+			continue
+		}
+
+		oldValue, newValue := eventValue(old, thresholds), eventValue(u, thresholds)
+		if oldValue.State != newValue.State {
+			e := newEvent(EventThresholdChanged, u)
+			e.Old, e.New = oldValue, newValue
+			events = append(events, e)
+		}
+		change := u.PercentageUsed - old.PercentageUsed
+		if change > minChange || change < -minChange {
+			e := newEvent(EventUsageChanged, u)
+			e.Old, e.New = oldValue, newValue
+			events = append(events, e)
+		}
+	}
+
+	for k, u := range before {
+		if _, exists := after[k]; !exists {
+			e := newEvent(EventRemoved, u)
+			e.Old = eventValue(u, thresholds)
+			events = append(events, e)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Namespace != events[j].Namespace {
+			return events[i].Namespace < events[j].Namespace
+		}
+		if events[i].PVC != events[j].PVC {
+			return events[i].PVC < events[j].PVC
+		}
+		return events[i].Type < events[j].Type
+	})
+	return events
+}
+
+// EventTracker turns successive refreshes into events. The min-change check
+// compares each PVC with its value at its last event rather than with the
+// previous refresh, so that slow growth is reported once it adds up.
+type EventTracker struct {
+	minChange  float64
+	thresholds Thresholds
+	baseline   map[string]Usage // value of each PVC at its last event
+}
+
+// NewEventTracker creates a tracker. The first refresh reports every PVC as added.
+func NewEventTracker(minChange float64, thresholds Thresholds) *EventTracker {
+	return &EventTracker{minChange: minChange, thresholds: thresholds, baseline: make(map[string]Usage)}
+}
+
+// Update returns the events of a refresh and moves the baseline of the PVCs
+// they concern
+func (t *EventTracker) Update(current []Usage, now time.Time) []Event {
+	key := func(namespace, name string) string { return namespace + "/" + name }
+
+	previous := make([]Usage, 0, len(t.baseline))
+	for _, u := range t.baseline {
+		previous = append(previous, u)
+	}
+	events := DiffUsages(previous, current, t.minChange, t.thresholds, now)
+
+	byKey := make(map[string]Usage, len(current))
+	for _, u := range current {
+		byKey[key(u.Namespace, u.PVC)] = u
+	}
+	for i, e := range events {
+		events[i].MinChange = &t.minChange
+		k := key(e.Namespace, e.PVC)
+		if e.Type == EventRemoved {
+			delete(t.baseline, k)
+		} else {
+			t.baseline[k] = byKey[k]
+		}
+	}
+	return events
+}
+
+// FilterEvents keeps the events whose old or new usage matches a usage filter
+// like ">80", so that a PVC crossing the filter boundary is still reported.
+// The kept events record the filter.
+func FilterEvents(events []Event, filter string) ([]Event, error) {
+	operator, value, err := ParseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []Event
+	for _, e := range events {
+		if (e.Old != nil && matchFilter(operator, value, e.Old.PercentageUsed)) ||
+			(e.New != nil && matchFilter(operator, value, e.New.PercentageUsed)) {
+			e.Filter = filter
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,127 @@
+package pvc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThresholdsState(t *testing.T) {
+	thresholds := Thresholds{Warn: 80, Crit: 90}
+	tests := []struct {
+		input    float64
+		expected string
+	}{
+		{10, StateOK},
+		{80, StateWarning},
+		{89.9, StateWarning},
+		{90, StateCritical},
+	}
+
+	for _, tt := range tests {
+		if got := thresholds.State(tt.input); got != tt.expected {
+			t.Errorf("State(%v) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestDiffUsages(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	thresholds := Thresholds{Warn: 80, Crit: 90}
+
+	previous := []Usage{
+		{Namespace: "ns", PVC: "steady", PercentageUsed: 50},
+		{Namespace: "ns", PVC: "growing", PercentageUsed: 50},
+		{Namespace: "ns", PVC: "crossing", PercentageUsed: 79.8},
+		{Namespace: "ns", PVC: "deleted", PercentageUsed: 10},
+	}
+	current := []Usage{
+		{Namespace: "ns", PVC: "steady", PercentageUsed: 50.5},
+		{Namespace: "ns", PVC: "growing", PercentageUsed: 55},
+		{Namespace: "ns", PVC: "crossing", PercentageUsed: 80.1},
+		{Namespace: "ns", PVC: "new", PercentageUsed: 95},
+	}
+
+	events := DiffUsages(previous, current, 1, thresholds, now)
+
+	want := []struct{ pvc, eventType string }{
+		{"crossing", EventThresholdChanged},
+		{"deleted", EventRemoved},
+		{"growing", EventUsageChanged},
+		{"new", EventAdded},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("DiffUsages() returned %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].PVC != w.pvc || events[i].Type != w.eventType {
+			t.Errorf("event %d = %s %s, want %s %s", i, events[i].Type, events[i].PVC, w.eventType, w.pvc)
+		}
+	}
+
+	crossing := events[0]
+	if crossing.Old.State != StateOK || crossing.New.State != StateWarning || !crossing.Time.Equal(now) {
+		t.Errorf("threshold event = %+v old=%+v new=%+v", crossing, crossing.Old, crossing.New)
+	}
+	if events[1].New != nil || events[1].Old == nil {
+		t.Errorf("removed event should only have an old value")
+	}
+	if events[3].Old != nil || events[3].New.State != StateCritical {
+		t.Errorf("added event should only have a new value")
+	}
+}
+
+func TestEventTrackerSlowGrowth(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewEventTracker(1, Thresholds{Warn: 80, Crit: 90})
+
+	if events := tracker.Update([]Usage{{Namespace: "ns", PVC: "data", PercentageUsed: 50}}, now); len(events) != 1 || events[0].Type != EventAdded {
+		t.Fatalf("first Update() = %+v, want one added event", events)
+	}
+
+	// 0.3 points per refresh: only the refresh that adds up to more than 1 point emits
+	var got []Event
+	for i, pct := range []float64{50.3, 50.6, 50.9, 51.2, 51.5} {
+		events := tracker.Update([]Usage{{Namespace: "ns", PVC: "data", PercentageUsed: pct}}, now.Add(time.Duration(i+1)*5*time.Second))
+		got = append(got, events...)
+	}
+	if len(got) != 1 || got[0].Type != EventUsageChanged {
+		t.Fatalf("Update() over small steps = %+v, want one usage_changed event", got)
+	}
+	if got[0].Old.PercentageUsed != 50 || got[0].New.PercentageUsed != 51.2 {
+		t.Errorf("usage_changed from %v to %v, want 50 to 51.2", got[0].Old.PercentageUsed, got[0].New.PercentageUsed)
+	}
+	if got[0].MinChange == nil || *got[0].MinChange != 1 {
+		t.Errorf("event minChange = %v, want 1", got[0].MinChange)
+	}
+
+	// The baseline moved to 51.2, so 51.5 is not a change yet
+	if events := tracker.Update([]Usage{{Namespace: "ns", PVC: "data", PercentageUsed: 51.5}}, now); len(events) != 0 {
+		t.Errorf("Update() after the event = %+v, want none", events)
+	}
+	if events := tracker.Update(nil, now); len(events) != 1 || events[0].Type != EventRemoved {
+		t.Errorf("Update() without the PVC = %+v, want one removed event", events)
+	}
+}
+
+func TestFilterEvents(t *testing.T) {
+	events := []Event{
+		{PVC: "crossing", Type: EventUsageChanged, Old: &EventValue{PercentageUsed: 45}, New: &EventValue{PercentageUsed: 55}},
+		{PVC: "low", Type: EventUsageChanged, Old: &EventValue{PercentageUsed: 10}, New: &EventValue{PercentageUsed: 20}},
+		{PVC: "new", Type: EventAdded, New: &EventValue{PercentageUsed: 60}},
+		{PVC: "gone", Type: EventRemoved, Old: &EventValue{PercentageUsed: 5}},
+	}
+
+	got, err := FilterEvents(events, ">50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].PVC != "crossing" || got[1].PVC != "new" {
+		t.Errorf("FilterEvents() = %+v, want crossing and new", got)
+	}
+	if got[0].Filter != ">50" {
+		t.Errorf("FilterEvents() event filter = %q, want %q", got[0].Filter, ">50")
+	}
+	if _, err := FilterEvents(events, "bogus"); err == nil {
+		t.Error("FilterEvents() with an invalid filter returned no error")
+	}
+}
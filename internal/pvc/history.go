@@ -0,0 +1,50 @@
+package pvc
+
+// historyGrace is how many refreshes in a row a PVC can be missing before its
+// samples are forgotten, so that a node whose stats failed once keeps the
+// trend of its PVCs
+const historyGrace = 3
+
+// History keeps the most recent usage percentages of each PVC
+type History struct {
+	size    int
+	samples map[string][]float64
+	missing map[string]int // refreshes in a row each PVC was missing from
+}
+
+// NewHistory creates a history that keeps the last size samples per PVC
+func NewHistory(size int) *History {
+	return &History{size: size, samples: make(map[string][]float64), missing: make(map[string]int)}
+}
+
+// Add records a sample for every usage. PVCs missing from more than
+// historyGrace refreshes in a row are forgotten.
+func (h *History) Add(usages []Usage) {
+	seen := make(map[string]bool, len(usages))
+	for _, u := range usages {
+		key := u.Namespace + "/" + u.PVC
+		seen[key] = true
+		samples := append(h.samples[key], u.PercentageUsed)
+		if len(samples) > h.size {
+			samples = samples[len(samples)-h.size:]
+		}
+		h.samples[key] = samples
+		delete(h.missing, key)
+	}
+
+	for key := range h.samples {
+		if seen[key] {
+			continue
+		}
+		h.missing[key]++
+		if h.missing[key] > historyGrace {
+			delete(h.samples, key)
+			delete(h.missing, key)
+		}
+	}
+}
+
+// Get returns the recorded samples of a PVC, oldest first
+func (h *History) Get(namespace, name string) []float64 {
+	return h.samples[namespace+"/"+name]
+}
@@ -0,0 +1,125 @@
+package pvc
+
+import (
+	"log"
+	"sort"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+// NodeUsage is the local storage usage of a node
+type NodeUsage struct {
+	Node         string   `json:"node"`
+	DiskPressure bool     `json:"diskPressure"`
+	Fs           *FsUsage `json:"fs,omitempty"`      // root filesystem of the kubelet
+	ImageFs      *FsUsage `json:"imageFs,omitempty"` // filesystem of the container images
+	// EphemeralBytes is the ephemeral storage used by all the pods counted, not only TopPods
+	EphemeralBytes int64        `json:"ephemeralBytes"`
+	TopPods        []PodStorage `json:"topPods,omitempty"`
+}
+
+// FsUsage is the usage of a node filesystem
+type FsUsage struct {
+	CapacityBytes  int64   `json:"capacityBytes"`
+	UsedBytes      int64   `json:"usedBytes"`
+	AvailableBytes int64   `json:"availableBytes"`
+	PercentageUsed float64 `json:"percentageUsed"`
+}
+
+// PodStorage is the ephemeral storage used by a pod
+type PodStorage struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	UsedBytes int64  `json:"usedBytes"`
+}
+
+// GetNodeUsages reads the stats summary of every node matching nodeSelector.
+// Pods are limited to namespace unless it is empty, and only the topPods
+// using the most ephemeral storage are kept. Nodes are ordered by root
+// filesystem usage, fullest first.
+func GetNodeUsages(client *k8s.Client, nodeSelector, namespace string, topPods int) ([]NodeUsage, error) {
+	pressure, err := client.GetNodeDiskPressure(nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []NodeUsage
+	for node, diskPressure := range pressure {
+		summary, err := client.GetSummary(node)
+		if err != nil {
+			// Log error but continue with other nodes
+			log.Printf("Error getting summary for node %s: %v", node, err)
+			continue
+		}
+		nodes = append(nodes, SummarizeNode(node, summary, diskPressure, namespace, topPods))
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if a.Fs == nil || b.Fs == nil {
+			if (a.Fs == nil) != (b.Fs == nil) {
+				return b.Fs == nil
+			}
+			return a.Node < b.Node
+		}
+		if a.Fs.PercentageUsed != b.Fs.PercentageUsed {
+			return a.Fs.PercentageUsed > b.Fs.PercentageUsed
+		}
+		return a.Node < b.Node
+	})
+	return nodes, nil
+}
+
+// SummarizeNode builds the usage of a node from its stats summary. Pods outside
+// namespace are skipped unless it is empty, and only the topPods using the most
+// ephemeral storage are listed (all of them if topPods is 0).
+func SummarizeNode(node string, summary *k8s.Summary, diskPressure bool, namespace string, topPods int) NodeUsage {
+	usage := NodeUsage{
+		Node:         node,
+		DiskPressure: diskPressure,
+		Fs:           fsUsage(summary.Node.Fs),
+	}
+	if summary.Node.Runtime != nil {
+		usage.ImageFs = fsUsage(summary.Node.Runtime.ImageFs)
+	}
+
+	for _, pod := range summary.Pods {
+		if pod.EphemeralStorage == nil || pod.EphemeralStorage.UsedBytes == 0 {
+			continue
+		}
+		if namespace != "" && pod.PodRef.Namespace != namespace {
+			continue
+		}
+		usage.EphemeralBytes += pod.EphemeralStorage.UsedBytes
+		usage.TopPods = append(usage.TopPods, PodStorage{
+			Namespace: pod.PodRef.Namespace,
+			Pod:       pod.PodRef.Name,
+			UsedBytes: pod.EphemeralStorage.UsedBytes,
+		})
+	}
+
+	sort.Slice(usage.TopPods, func(i, j int) bool {
+		a, b := usage.TopPods[i], usage.TopPods[j]
+		if a.UsedBytes != b.UsedBytes {
+			return a.UsedBytes > b.UsedBytes
+		}
+		return a.Namespace+"/"+a.Pod < b.Namespace+"/"+b.Pod
+	})
+	if topPods > 0 && len(usage.TopPods) > topPods {
+		usage.TopPods = usage.TopPods[:topPods]
+	}
+	return usage
+}
+
+// fsUsage converts filesystem stats, returning nil when the kubelet has none
+func fsUsage(stats *k8s.FsStats) *FsUsage {
+	if stats == nil || stats.CapacityBytes == 0 {
+		return nil
+	}
+	return &FsUsage{
+		CapacityBytes:  stats.CapacityBytes,
+		UsedBytes:      stats.UsedBytes,
+		AvailableBytes: stats.AvailableBytes,
+		PercentageUsed: float64(stats.UsedBytes) / float64(stats.CapacityBytes) * 100,
+	}
+}
@@ -0,0 +1,83 @@
+package pvc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+const nodeSummary = `{
+	"node": {
+		"nodeName": "worker-1",
+		"fs": {"capacityBytes": 100, "usedBytes": 85, "availableBytes": 15},
+		"runtime": {"imageFs": {"capacityBytes": 200, "usedBytes": 50, "availableBytes": 150}}
+	},
+	"pods": [
+		{"podRef": {"namespace": "web", "name": "api-0"}, "ephemeral-storage": {"usedBytes": 10}},
+		{"podRef": {"namespace": "web", "name": "api-1"}, "ephemeral-storage": {"usedBytes": 30}},
+		{"podRef": {"namespace": "batch", "name": "job-x"}, "ephemeral-storage": {"usedBytes": 20}},
+		{"podRef": {"namespace": "web", "name": "idle"}, "ephemeral-storage": {"usedBytes": 0}},
+		{"podRef": {"namespace": "web", "name": "starting"}}
+	]
+}`
+
+func TestSummarizeNode(t *testing.T) {
+	var summary k8s.Summary
+	if err := json.Unmarshal([]byte(nodeSummary), &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		topPods   int
+		want      NodeUsage
+	}{
+		{
+			name:    "top 2 of all namespaces",
+			topPods: 2,
+			want: NodeUsage{
+				Node:           "worker-1",
+				DiskPressure:   true,
+				Fs:             &FsUsage{CapacityBytes: 100, UsedBytes: 85, AvailableBytes: 15, PercentageUsed: 85},
+				ImageFs:        &FsUsage{CapacityBytes: 200, UsedBytes: 50, AvailableBytes: 150, PercentageUsed: 25},
+				EphemeralBytes: 60,
+				TopPods: []PodStorage{
+					{Namespace: "web", Pod: "api-1", UsedBytes: 30},
+					{Namespace: "batch", Pod: "job-x", UsedBytes: 20},
+				},
+			},
+		},
+		{
+			name:      "all pods of a namespace",
+			namespace: "web",
+			want: NodeUsage{
+				Node:           "worker-1",
+				DiskPressure:   true,
+				Fs:             &FsUsage{CapacityBytes: 100, UsedBytes: 85, AvailableBytes: 15, PercentageUsed: 85},
+				ImageFs:        &FsUsage{CapacityBytes: 200, UsedBytes: 50, AvailableBytes: 150, PercentageUsed: 25},
+				EphemeralBytes: 40,
+				TopPods: []PodStorage{
+					{Namespace: "web", Pod: "api-1", UsedBytes: 30},
+					{Namespace: "web", Pod: "api-0", UsedBytes: 10},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := SummarizeNode("worker-1", &summary, true, tt.namespace, tt.topPods)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SummarizeNode() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeNodeWithoutFsStats(t *testing.T) {
+	got := SummarizeNode("worker-2", &k8s.Summary{}, false, "", 5)
+	if got.Fs != nil || got.ImageFs != nil || got.TopPods != nil {
+		t.Errorf("SummarizeNode() = %+v, want no filesystems or pods", got)
+	}
+}
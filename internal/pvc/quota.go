@@ -0,0 +1,115 @@
+package pvc
+
+import (
+	"sort"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+// NamespaceQuota compares the storage quotas of a namespace with its PVCs
+type NamespaceQuota struct {
+	Namespace      string             `json:"namespace"`
+	PVCs           int                `json:"pvcs"`
+	RequestedBytes int64              `json:"requestedBytes"` // sum of the PVC requests
+	UsedBytes      int64              `json:"usedBytes"`      // data written to the mounted PVCs
+	Quotas         []k8s.StorageQuota `json:"quotas,omitempty"`
+	// MaxPVCBytes is the LimitRange maximum size of a PVC, 0 without one
+	MaxPVCBytes int64 `json:"maxPVCBytes,omitempty"`
+	// Blocked lists the limits that would reject expanding nearly-full PVCs
+	Blocked []Blocker `json:"blocked,omitempty"`
+}
+
+// Blocker is a limit that would reject the expansion of a nearly-full PVC
+type Blocker struct {
+	PVC            string  `json:"pvc"`
+	PercentageUsed float64 `json:"percentageUsed"`
+	GrowthBytes    int64   `json:"growthBytes"`
+	TargetBytes    int64   `json:"targetBytes"`
+	// Limit is "LimitRange", or the name of the quota
+	Limit        string `json:"limit"`
+	StorageClass string `json:"storageClass,omitempty"` // of a per-StorageClass quota
+	// AllowedBytes is the LimitRange maximum size, or the space left in the quota
+	AllowedBytes int64 `json:"allowedBytes"`
+}
+
+// ExpansionPolicy decides which PVCs need expanding and by how much
+type ExpansionPolicy struct {
+	NearlyFull float64 // usage percentage from which a PVC needs expanding
+	ExpandBy   float64 // expansion, in percent of the current request
+}
+
+// EvaluateQuotas groups the claims and quotas by namespace, ordered by name,
+// and checks whether the nearly-full PVCs could be expanded under the policy
+func EvaluateQuotas(quotas []k8s.StorageQuota, maxPVCBytes map[string]int64, sizes []k8s.ClaimSize, usages []Usage, policy ExpansionPolicy) []NamespaceQuota {
+	namespaces := make(map[string]*NamespaceQuota)
+	get := func(namespace string) *NamespaceQuota {
+		n, ok := namespaces[namespace]
+		if !ok {
+			n = &NamespaceQuota{Namespace: namespace, MaxPVCBytes: maxPVCBytes[namespace]}
+			namespaces[namespace] = n
+		}
+		return n
+	}
+
+	for _, q := range quotas {
+		n := get(q.Namespace)
+		n.Quotas = append(n.Quotas, q)
+	}
+
+	usageOf := make(map[k8s.ClaimKey]Usage)
+	for _, u := range usages {
+		usageOf[k8s.ClaimKey{Namespace: u.Namespace, Name: u.PVC}] = u
+	}
+
+	// Expansions are checked one at a time, as each could be the only one requested
+	for _, size := range sizes {
+		n := get(size.Namespace)
+		n.PVCs++
+		n.RequestedBytes += size.RequestedBytes
+		u, mounted := usageOf[k8s.ClaimKey{Namespace: size.Namespace, Name: size.PVC}]
+		if !mounted {
+			continue
+		}
+		n.UsedBytes += u.UsedBytes
+		if u.PercentageUsed < policy.NearlyFull {
+			continue
+		}
+		n.Blocked = append(n.Blocked, expansionBlockers(size, u, n, policy)...)
+	}
+
+	result := make([]NamespaceQuota, 0, len(namespaces))
+	for _, n := range namespaces {
+		result = append(result, *n)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Namespace < result[j].Namespace })
+	return result
+}
+
+// expansionBlockers returns the limits that would reject growing a PVC by the policy
+func expansionBlockers(size k8s.ClaimSize, u Usage, n *NamespaceQuota, policy ExpansionPolicy) []Blocker {
+	growth := int64(float64(size.RequestedBytes) * policy.ExpandBy / 100)
+	blocker := Blocker{
+		PVC:            size.PVC,
+		PercentageUsed: u.PercentageUsed,
+		GrowthBytes:    growth,
+		TargetBytes:    size.RequestedBytes + growth,
+	}
+
+	var blockers []Blocker
+	if n.MaxPVCBytes > 0 && blocker.TargetBytes > n.MaxPVCBytes {
+		b := blocker
+		b.Limit, b.AllowedBytes = "LimitRange", n.MaxPVCBytes
+		blockers = append(blockers, b)
+	}
+	for _, q := range n.Quotas {
+		if q.Resource != "requests.storage" || (q.StorageClass != "" && q.StorageClass != size.StorageClass) {
+			continue
+		}
+		if left := max(q.Hard-q.Used, 0); growth > left {
+			b := blocker
+			b.Limit, b.StorageClass, b.AllowedBytes = q.Quota, q.StorageClass, left
+			blockers = append(blockers, b)
+		}
+	}
+	return blockers
+}
@@ -0,0 +1,53 @@
+package pvc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+func TestEvaluateQuotas(t *testing.T) {
+	quotas := []k8s.StorageQuota{
+		{Namespace: "kafka", Quota: "storage", Resource: "requests.storage", Hard: 100, Used: 90},
+		{Namespace: "kafka", Quota: "storage", Resource: "persistentvolumeclaims", Hard: 5, Used: 3},
+		{Namespace: "kafka", Quota: "storage", Resource: "requests.storage", StorageClass: "gold", Hard: 50, Used: 50},
+		{Namespace: "empty", Quota: "storage", Resource: "requests.storage", Hard: 10},
+	}
+	maxPVCBytes := map[string]int64{"kafka": 60}
+	sizes := []k8s.ClaimSize{
+		{Namespace: "kafka", PVC: "data-0", StorageClass: "gold", RequestedBytes: 50},
+		{Namespace: "kafka", PVC: "data-1", RequestedBytes: 20},
+		{Namespace: "kafka", PVC: "logs", RequestedBytes: 20},
+		{Namespace: "web", PVC: "uploads", RequestedBytes: 10},
+	}
+	usages := []Usage{
+		{Namespace: "kafka", PVC: "data-0", UsedBytes: 45, PercentageUsed: 90},
+		{Namespace: "kafka", PVC: "data-1", UsedBytes: 17, PercentageUsed: 85},
+		{Namespace: "kafka", PVC: "logs", UsedBytes: 2, PercentageUsed: 10},
+		{Namespace: "web", PVC: "uploads", UsedBytes: 9, PercentageUsed: 90},
+	}
+
+	result := EvaluateQuotas(quotas, maxPVCBytes, sizes, usages, ExpansionPolicy{NearlyFull: 80, ExpandBy: 50})
+	if len(result) != 3 || result[0].Namespace != "empty" || result[1].Namespace != "kafka" || result[2].Namespace != "web" {
+		t.Fatalf("EvaluateQuotas() = %+v, want empty, kafka and web", result)
+	}
+
+	kafka := result[1]
+	if kafka.PVCs != 3 || kafka.RequestedBytes != 90 || kafka.UsedBytes != 64 || len(kafka.Quotas) != 3 || kafka.MaxPVCBytes != 60 {
+		t.Errorf("EvaluateQuotas() kafka = %+v", kafka)
+	}
+	expected := []Blocker{
+		{PVC: "data-0", PercentageUsed: 90, GrowthBytes: 25, TargetBytes: 75, Limit: "LimitRange", AllowedBytes: 60},
+		{PVC: "data-0", PercentageUsed: 90, GrowthBytes: 25, TargetBytes: 75, Limit: "storage", AllowedBytes: 10},
+		{PVC: "data-0", PercentageUsed: 90, GrowthBytes: 25, TargetBytes: 75, Limit: "storage", StorageClass: "gold", AllowedBytes: 0},
+	}
+	if !reflect.DeepEqual(kafka.Blocked, expected) {
+		t.Errorf("EvaluateQuotas() kafka blockers =\n%+v\nwant\n%+v", kafka.Blocked, expected)
+	}
+
+	// Without quotas nothing blocks the expansion
+	if web := result[2]; web.Blocked != nil || web.PVCs != 1 {
+		t.Errorf("EvaluateQuotas() web = %+v, want 1 PVC and no blockers", web)
+	}
+}
@@ -0,0 +1,209 @@
+package pvc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+// Recommendation actions
+const (
+	ActionGrow   = "grow"
+	ActionShrink = "shrink"
+	ActionKeep   = "keep"
+)
+
+// UsageSample is the used space of a PVC from a point in time on
+type UsageSample struct {
+	Time      time.Time
+	UsedBytes int64
+}
+
+// Policy sizes PVCs as a percentile of their usage plus headroom, rounded up
+type Policy struct {
+	Percentile float64 // of the used space over the history, weighted by time
+	Headroom   float64 // percentage added to the percentile
+	RoundTo    int64   // target sizes are rounded up to a multiple of this
+}
+
+// Recommendation is the target size of a PVC under a policy
+type Recommendation struct {
+	Namespace    string `json:"namespace"`
+	PVC          string `json:"pvc"`
+	StorageClass string `json:"storageClass,omitempty"`
+	CurrentBytes int64  `json:"currentBytes"`
+	// UsedBytes is the usage percentile the target is based on
+	UsedBytes   int64  `json:"usedBytes"`
+	Samples     int    `json:"samples"` // 1 when only the current usage is known
+	TargetBytes int64  `json:"targetBytes"`
+	Action      string `json:"action"`
+	// Expandable is set when the StorageClass allows growing the PVC in place
+	Expandable bool `json:"expandable"`
+}
+
+// ReadEventLog reads the used space of each PVC from a watch -o events log.
+// Samples before since are dropped, except the last one of each PVC, which
+// still holds at the start of the window. Logs written with a non-zero
+// --event-min-change or with --filter leave usage changes out, which would
+// bias the percentile low, so they are refused. Logs that don't record these
+// settings are read with a warning.
+func ReadEventLog(path string, since time.Time) (map[k8s.ClaimKey][]UsageSample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening event log: %v", err)
+	}
+	defer file.Close()
+
+	samples := make(map[k8s.ClaimKey][]UsageSample)
+	unknownSettings := false
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid event: %v", path, line, err)
+		}
+		switch {
+		case e.MinChange == nil:
+			unknownSettings = true
+		case *e.MinChange != 0:
+			return nil, fmt.Errorf("%s:%d: the log was written with --event-min-change %v, it leaves out smaller usage changes; write it with --event-min-change 0",
+				path, line, *e.MinChange)
+		}
+		if e.Filter != "" {
+			return nil, fmt.Errorf("%s:%d: the log was written with --filter %q, it leaves out the PVCs outside the filter; write it without --filter",
+				path, line, e.Filter)
+		}
+		// Removed events carry no new value
+		if e.New == nil {
+			continue
+		}
+
+		key := k8s.ClaimKey{Namespace: e.Namespace, Name: e.PVC}
+		sample := UsageSample{Time: e.Time, UsedBytes: e.New.UsedBytes}
+		if sample.Time.Before(since) {
+			sample.Time = since
+			// Only the latest value before the window matters
+			if s := samples[key]; len(s) == 1 && s[0].Time.Equal(since) {
+				s[0] = sample
+				continue
+			}
+		}
+		samples[key] = append(samples[key], sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading event log: %v", err)
+	}
+	if unknownSettings {
+		log.Printf("Warning: %s has events that don't record their --event-min-change, recommendations are too low if it wasn't 0", path)
+	}
+	return samples, nil
+}
+
+// Recommend computes a target size for each claim with a known usage. The
+// history is used when it has samples of the claim, the current usage otherwise.
+func Recommend(sizes []k8s.ClaimSize, usages []Usage, history map[k8s.ClaimKey][]UsageSample, policy Policy, now time.Time) []Recommendation {
+	current := make(map[k8s.ClaimKey]int64)
+	for _, u := range usages {
+		current[k8s.ClaimKey{Namespace: u.Namespace, Name: u.PVC}] = u.UsedBytes
+	}
+
+	var recommendations []Recommendation
+	for _, size := range sizes {
+		key := k8s.ClaimKey{Namespace: size.Namespace, Name: size.PVC}
+		samples := history[key]
+		if used, ok := current[key]; ok {
+			samples = append(samples[:len(samples):len(samples)], UsageSample{Time: now, UsedBytes: used})
+		}
+		if len(samples) == 0 {
+			continue
+		}
+
+		r := Recommendation{
+			Namespace:    size.Namespace,
+			PVC:          size.PVC,
+			StorageClass: size.StorageClass,
+			CurrentBytes: size.StatusBytes,
+			UsedBytes:    usagePercentile(samples, policy.Percentile, now),
+			Samples:      len(samples),
+		}
+		if r.CurrentBytes == 0 {
+			r.CurrentBytes = size.RequestedBytes
+		}
+		r.TargetBytes = roundUp(int64(float64(r.UsedBytes)*(1+policy.Headroom/100)), policy.RoundTo)
+
+		switch {
+		// A pending expansion already requested the target
+		case r.TargetBytes > r.CurrentBytes && r.TargetBytes > size.RequestedBytes:
+			r.Action = ActionGrow
+		case r.TargetBytes < r.CurrentBytes:
+			r.Action = ActionShrink
+		default:
+			r.Action = ActionKeep
+		}
+		recommendations = append(recommendations, r)
+	}
+	return recommendations
+}
+
+// usagePercentile returns the used space that the PVC stayed at or below for
+// p percent of the time. Each sample holds until the next one, the last until now.
+func usagePercentile(samples []UsageSample, p float64, now time.Time) int64 {
+	sorted := make([]UsageSample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	type weighted struct {
+		used   int64
+		weight float64
+	}
+	values := make([]weighted, len(sorted))
+	total := 0.0
+	for i, s := range sorted {
+		end := now
+		if i+1 < len(sorted) {
+			end = sorted[i+1].Time
+		}
+		values[i] = weighted{s.UsedBytes, math.Max(end.Sub(s.Time).Seconds(), 0)}
+		total += values[i].weight
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].used < values[j].used })
+
+	// Without a duration, e.g. a single current sample, every value counts once
+	if total == 0 {
+		for i := range values {
+			values[i].weight = 1
+		}
+		total = float64(len(values))
+	}
+
+	cumulative := 0.0
+	for _, v := range values {
+		cumulative += v.weight
+		if cumulative >= p/100*total {
+			return v.used
+		}
+	}
+	return values[len(values)-1].used
+}
+
+// roundUp rounds bytes up to a multiple of unit, and to at least one unit
+func roundUp(bytes, unit int64) int64 {
+	if unit <= 0 {
+		return bytes
+	}
+	units := (bytes + unit - 1) / unit
+	if units < 1 {
+		units = 1
+	}
+	return units * unit
+}
@@ -0,0 +1,130 @@
+package pvc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+func TestUsagePercentile(t *testing.T) {
+	start := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int, used int64) UsageSample {
+		return UsageSample{Time: start.Add(time.Duration(hours) * time.Hour), UsedBytes: used}
+	}
+
+	tests := []struct {
+		name     string
+		samples  []UsageSample
+		p        float64
+		expected int64
+	}{
+		{"single", []UsageSample{at(100, 42)}, 95, 42},
+		// A short spike is below the 95th percentile of time
+		{"spike", []UsageSample{at(0, 10), at(50, 20), at(99, 80)}, 95, 20},
+		{"spike max", []UsageSample{at(0, 10), at(50, 20), at(99, 80)}, 100, 80},
+		{"unordered", []UsageSample{at(50, 30), at(0, 10)}, 50, 10},
+	}
+
+	now := start.Add(100 * time.Hour)
+	for _, tt := range tests {
+		if got := usagePercentile(tt.samples, tt.p, now); got != tt.expected {
+			t.Errorf("%s: usagePercentile() = %d, want %d", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct {
+		bytes, unit, expected int64
+	}{
+		{13, 10, 20},
+		{20, 10, 20},
+		{0, 10, 10},
+		{13, 0, 13},
+	}
+
+	for _, tt := range tests {
+		if got := roundUp(tt.bytes, tt.unit); got != tt.expected {
+			t.Errorf("roundUp(%d, %d) = %d, want %d", tt.bytes, tt.unit, got, tt.expected)
+		}
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	now := time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC)
+	policy := Policy{Percentile: 95, Headroom: 30, RoundTo: 10}
+	sizes := []k8s.ClaimSize{
+		{Namespace: "db", PVC: "full", StorageClass: "gp3", RequestedBytes: 50, StatusBytes: 50},
+		{Namespace: "db", PVC: "empty", RequestedBytes: 100, StatusBytes: 100},
+		{Namespace: "db", PVC: "fit", RequestedBytes: 40, StatusBytes: 40},
+		{Namespace: "db", PVC: "expanding", RequestedBytes: 80, StatusBytes: 50},
+		{Namespace: "db", PVC: "unknown", RequestedBytes: 10, StatusBytes: 10},
+	}
+	usages := []Usage{
+		{Namespace: "db", PVC: "full", UsedBytes: 48},
+		{Namespace: "db", PVC: "fit", UsedBytes: 30},
+		{Namespace: "db", PVC: "expanding", UsedBytes: 49},
+	}
+	history := map[k8s.ClaimKey][]UsageSample{
+		{Namespace: "db", Name: "empty"}: {{Time: now.Add(-24 * time.Hour), UsedBytes: 5}},
+	}
+
+	expected := []Recommendation{
+		{Namespace: "db", PVC: "full", StorageClass: "gp3", CurrentBytes: 50, UsedBytes: 48, Samples: 1, TargetBytes: 70, Action: ActionGrow},
+		{Namespace: "db", PVC: "empty", CurrentBytes: 100, UsedBytes: 5, Samples: 1, TargetBytes: 10, Action: ActionShrink},
+		{Namespace: "db", PVC: "fit", CurrentBytes: 40, UsedBytes: 30, Samples: 1, TargetBytes: 40, Action: ActionKeep},
+		{Namespace: "db", PVC: "expanding", CurrentBytes: 50, UsedBytes: 49, Samples: 1, TargetBytes: 70, Action: ActionKeep},
+	}
+	if got := Recommend(sizes, usages, history, policy, now); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Recommend() =\n%+v\nwant\n%+v", got, expected)
+	}
+}
+
+func TestReadEventLog(t *testing.T) {
+	log := `{"time":"2025-03-01T00:00:00Z","type":"added","namespace":"db","pvc":"data","new":{"usedBytes":10}}
+{"time":"2025-03-20T00:00:00Z","type":"usage_changed","namespace":"db","pvc":"data","old":{"usedBytes":10},"new":{"usedBytes":20}}
+
+{"time":"2025-04-05T00:00:00Z","type":"usage_changed","namespace":"db","pvc":"data","old":{"usedBytes":20},"new":{"usedBytes":30}}
+{"time":"2025-04-06T00:00:00Z","type":"removed","namespace":"db","pvc":"data","old":{"usedBytes":30}}
+`
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	since := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	samples, err := ReadEventLog(path, since)
+	if err != nil {
+		t.Fatalf("ReadEventLog() unexpected error: %v", err)
+	}
+	expected := []UsageSample{
+		{Time: since, UsedBytes: 20},
+		{Time: time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC), UsedBytes: 30},
+	}
+	if got := samples[k8s.ClaimKey{Namespace: "db", Name: "data"}]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("ReadEventLog() = %+v, want %+v", got, expected)
+	}
+
+	tests := []struct {
+		name    string
+		log     string
+		wantErr bool
+	}{
+		{"invalid line", "not json\n", true},
+		{"complete", `{"time":"2025-04-02T00:00:00Z","type":"added","namespace":"db","pvc":"data","new":{"usedBytes":10},"minChange":0}` + "\n", false},
+		{"min change", `{"time":"2025-04-02T00:00:00Z","type":"added","namespace":"db","pvc":"data","new":{"usedBytes":10},"minChange":1}` + "\n", true},
+		{"filter", `{"time":"2025-04-02T00:00:00Z","type":"added","namespace":"db","pvc":"data","new":{"usedBytes":10},"minChange":0,"filter":">50"}` + "\n", true},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.log), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadEventLog(path, since); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ReadEventLog() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package pvc
+
+// Totals holds the totals of a list of PVC usages
+type Totals struct {
+	PVCs           int     `json:"pvcs"`
+	CapacityBytes  int64   `json:"capacityBytes"`
+	UsedBytes      int64   `json:"usedBytes"`
+	AvailableBytes int64   `json:"availableBytes"`
+	PercentageUsed float64 `json:"percentageUsed"` // weighted by capacity
+	Warn           float64 `json:"warn"`
+	Crit           float64 `json:"crit"`
+	AboveWarn      int     `json:"aboveWarn"` // includes the PVCs above crit
+	AboveCrit      int     `json:"aboveCrit"`
+
+	// FullestNamespace is the namespace with the highest weighted usage
+	FullestNamespace               string  `json:"fullestNamespace,omitempty"`
+	FullestNamespacePercentageUsed float64 `json:"fullestNamespacePercentageUsed,omitempty"`
+}
+
+// Summarize computes the totals of usages, counting the PVCs at or above each threshold
+func Summarize(usages []Usage, thresholds Thresholds) Totals {
+	totals := Totals{PVCs: len(usages), Warn: thresholds.Warn, Crit: thresholds.Crit}
+	for _, u := range usages {
+		totals.CapacityBytes += u.CapacityBytes
+		totals.UsedBytes += u.UsedBytes
+		totals.AvailableBytes += u.AvailableBytes
+		switch thresholds.State(u.PercentageUsed) {
+		case StateCritical:
+			totals.AboveCrit++
+			totals.AboveWarn++
+		case StateWarning:
+			totals.AboveWarn++
+		}
+	}
+	if totals.CapacityBytes > 0 {
+		totals.PercentageUsed = float64(totals.UsedBytes) / float64(totals.CapacityBytes) * 100
+	}
+
+	// Groups come ordered by usage, the first one is the fullest
+	if groups, _ := GroupUsages(usages, "namespace"); len(groups) > 0 {
+		totals.FullestNamespace = groups[0].Key
+		totals.FullestNamespacePercentageUsed = groups[0].PercentageUsed
+	}
+	return totals
+}
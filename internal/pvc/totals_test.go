@@ -0,0 +1,35 @@
+package pvc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	thresholds := Thresholds{Warn: 80, Crit: 90}
+	usages := []Usage{
+		{Namespace: "kafka", PVC: "data-0", CapacityBytes: 100, UsedBytes: 95, AvailableBytes: 5, PercentageUsed: 95},
+		{Namespace: "kafka", PVC: "data-1", CapacityBytes: 100, UsedBytes: 45, AvailableBytes: 55, PercentageUsed: 45},
+		{Namespace: "web", PVC: "uploads", CapacityBytes: 50, UsedBytes: 40, AvailableBytes: 10, PercentageUsed: 80},
+		{Namespace: "web", PVC: "cache", CapacityBytes: 150, UsedBytes: 10, AvailableBytes: 140, PercentageUsed: 7},
+	}
+
+	tests := []struct {
+		name     string
+		usages   []Usage
+		expected Totals
+	}{
+		{"empty", nil, Totals{Warn: 80, Crit: 90}},
+		{"totals", usages, Totals{
+			PVCs: 4, CapacityBytes: 400, UsedBytes: 190, AvailableBytes: 210, PercentageUsed: 47.5,
+			Warn: 80, Crit: 90, AboveWarn: 2, AboveCrit: 1,
+			FullestNamespace: "kafka", FullestNamespacePercentageUsed: 70,
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := Summarize(tt.usages, thresholds); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: Summarize() = %+v, want %+v", tt.name, got, tt.expected)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package pvc
 
+import "github.com/joseEnrique/pvcusage/internal/k8s"
+
 // Summary represents the node stats summary structure.
 type Summary struct {
 	Pods []Pod `json:"pods"`
@@ -22,12 +24,26 @@ type Volume struct {
 	AvailableBytes int64 `json:"availableBytes"`
 }
 
+// Volume types of a Usage
+const (
+	VolumeTypePVC       = "pvc"
+	VolumeTypeEphemeral = "ephemeral" // PVC generated for a generic ephemeral volume
+	VolumeTypeEmptyDir  = k8s.InlineEmptyDir
+	VolumeTypeCSI       = k8s.InlineCSI
+)
+
 // Usage holds the PVC-related usage information for output.
+// Inline volumes are named <pod>/<volume> in the PVC field.
 type Usage struct {
-	Namespace      string
-	PVC            string
-	CapacityBytes  int64
-	UsedBytes      int64
-	AvailableBytes int64
-	PercentageUsed float64
+	Namespace      string   `json:"namespace"`
+	PVC            string   `json:"pvc"`
+	Type           string   `json:"type"`
+	CapacityBytes  int64    `json:"capacityBytes"`
+	UsedBytes      int64    `json:"usedBytes"`
+	AvailableBytes int64    `json:"availableBytes"`
+	PercentageUsed float64  `json:"percentageUsed"`
+	Pods           []string `json:"pods,omitempty"`
+	Workload       string   `json:"workload,omitempty"`
+	// Snapshots is only collected when requested
+	Snapshots *k8s.SnapshotStats `json:"snapshots,omitempty"`
 }
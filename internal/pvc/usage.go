@@ -2,43 +2,92 @@ package pvc
 
 import (
 	"fmt"
+	"log"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/joseEnrique/pvcusage/internal/k8s"
 )
 
-// GetUsages retrieves and calculates PVC usage across all nodes
-func GetUsages(client *k8s.Client) ([]Usage, error) {
-	nodes, err := client.GetNodes()
+// Options controls how PVC usage is collected
+type Options struct {
+	// Namespace limits collection to the PVCs of one namespace, "" for all
+	Namespace string
+	// NodeSelector limits collection to nodes matching this label selector
+	NodeSelector string
+	// IncludeInline adds emptyDir and CSI inline volumes to the PVCs
+	IncludeInline bool
+	// RequireAllNodes fails instead of skipping the nodes whose stats can't be
+	// read, whose PVCs would otherwise look deleted
+	RequireAllNodes bool
+}
+
+// GetUsages retrieves and calculates PVC usage across the nodes that host mounted PVCs
+func GetUsages(client *k8s.Client, opts Options) ([]Usage, error) {
+	volumes, err := client.GetPodVolumes(opts.Namespace, opts.NodeSelector, opts.IncludeInline)
 	if err != nil {
-		return nil, fmt.Errorf("error getting nodes: %v", err)
+		return nil, fmt.Errorf("error getting pod volumes: %v", err)
 	}
+	nodes, consumers, inline := volumes.Nodes, volumes.Consumers, volumes.Inline
 
 	var usages []Usage
+	seen := make(map[k8s.ClaimKey]bool)
 	for _, node := range nodes {
 		summary, err := client.GetSummary(node)
 		if err != nil {
+			if opts.RequireAllNodes {
+				return nil, fmt.Errorf("error getting summary for node %s: %v", node, err)
+			}
 			// Log error but continue with other nodes
-			fmt.Printf("Error getting summary for node %s: %v\n", node, err)
+			log.Printf("Error getting summary for node %s: %v", node, err)
 			continue
 		}
 
 		for _, pod := range summary.Pods {
+			if opts.Namespace != "" && pod.PodRef.Namespace != opts.Namespace {
+				continue
+			}
 			for _, vol := range pod.Volumes {
 				if vol.PVCRef != nil {
 					if vol.CapacityBytes == 0 {
 						continue
 					}
+					// A PVC shared by several pods is reported once per pod
+					key := k8s.ClaimKey{Namespace: vol.PVCRef.Namespace, Name: vol.PVCRef.Name}
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+
 					percentage := float64(vol.UsedBytes) / float64(vol.CapacityBytes) * 100
-					usages = append(usages, Usage{
+					usage := Usage{
 						Namespace:      vol.PVCRef.Namespace,
 						PVC:            vol.PVCRef.Name,
 						CapacityBytes:  vol.CapacityBytes,
 						UsedBytes:      vol.UsedBytes,
 						AvailableBytes: vol.AvailableBytes,
 						PercentageUsed: percentage,
-					})
+						Type:           VolumeTypePVC,
+					}
+					if consumer, ok := consumers[key]; ok {
+						usage.Pods = consumer.Pods
+						usage.Workload = consumer.Workload.String()
+						if consumer.Ephemeral {
+							usage.Type = VolumeTypeEphemeral
+						}
+					}
+					usages = append(usages, usage)
+					continue
+				}
+
+				// The kubelet also reports configMap, secret and projected volumes,
+				// only the inline volumes of the pod spec are kept
+				key := k8s.InlineVolumeKey{Namespace: pod.PodRef.Namespace, Pod: pod.PodRef.Name, Volume: vol.Name}
+				if volume, ok := inline[key]; ok {
+					if usage, ok := inlineUsage(key, vol, volume); ok {
+						usages = append(usages, usage)
+					}
 				}
 			}
 		}
@@ -52,6 +101,35 @@ func GetUsages(client *k8s.Client) ([]Usage, error) {
 	return usages, nil
 }
 
+// inlineUsage builds the usage of an emptyDir or CSI inline volume. An emptyDir
+// with a sizeLimit is measured against it instead of the node filesystem.
+// It returns false when the kubelet didn't report a capacity.
+func inlineUsage(key k8s.InlineVolumeKey, vol k8s.Volume, volume k8s.InlineVolume) (Usage, bool) {
+	capacity, available := vol.CapacityBytes, vol.AvailableBytes
+	if volume.SizeLimitBytes > 0 {
+		capacity = volume.SizeLimitBytes
+		available = capacity - vol.UsedBytes
+		if available < 0 {
+			available = 0
+		}
+	}
+	if capacity == 0 {
+		return Usage{}, false
+	}
+
+	return Usage{
+		Namespace:      key.Namespace,
+		PVC:            key.Pod + "/" + key.Volume,
+		Type:           volume.Type,
+		CapacityBytes:  capacity,
+		UsedBytes:      vol.UsedBytes,
+		AvailableBytes: available,
+		PercentageUsed: float64(vol.UsedBytes) / float64(capacity) * 100,
+		Pods:           []string{key.Pod},
+		Workload:       volume.Workload.String(),
+	}, true
+}
+
 // ParseFilter parses a filter string like ">50", "<=80", "=90" and returns the operator and value
 func ParseFilter(filter string) (string, float64, error) {
 	if filter == "" {
@@ -98,30 +176,33 @@ func FilterUsages(usages []Usage, filter string) ([]Usage, error) {
 
 	var filteredUsages []Usage
 	for _, u := range usages {
-		if operator != "" {
-			include := false
-			switch operator {
-			case ">":
-				include = u.PercentageUsed > value
-			case ">=":
-				include = u.PercentageUsed >= value
-			case "<":
-				include = u.PercentageUsed < value
-			case "<=":
-				include = u.PercentageUsed <= value
-			case "=":
-				include = u.PercentageUsed == value
-			}
-			if !include {
-				continue
-			}
+		if matchFilter(operator, value, u.PercentageUsed) {
+			filteredUsages = append(filteredUsages, u)
 		}
-		filteredUsages = append(filteredUsages, u)
 	}
 
 	return filteredUsages, nil
 }
 
+// matchFilter reports whether a usage percentage matches a parsed filter.
+// An empty operator matches everything.
+func matchFilter(operator string, value, percentage float64) bool {
+	switch operator {
+	case ">":
+		return percentage > value
+	case ">=":
+		return percentage >= value
+	case "<":
+		return percentage < value
+	case "<=":
+		return percentage <= value
+	case "=":
+		return percentage == value
+	default:
+		return true
+	}
+}
+
 // LimitTopN limits the usages list to the top N entries
 func LimitTopN(usages []Usage, n int) []Usage {
 	if n > 0 && len(usages) > n {
@@ -129,3 +210,129 @@ func LimitTopN(usages []Usage, n int) []Usage {
 	}
 	return usages
 }
+
+// SortUsages orders usages by "usage" (descending), "used" (descending),
+// "size" (descending), "name" or "namespace". An empty key sorts by usage.
+func SortUsages(usages []Usage, by string) error {
+	var less func(a, b Usage) bool
+	switch by {
+	case "", "usage":
+		less = func(a, b Usage) bool { return a.PercentageUsed > b.PercentageUsed }
+	case "used":
+		less = func(a, b Usage) bool { return a.UsedBytes > b.UsedBytes }
+	case "size":
+		less = func(a, b Usage) bool { return a.CapacityBytes > b.CapacityBytes }
+	case "name":
+		less = func(a, b Usage) bool { return a.PVC < b.PVC }
+	case "namespace":
+		less = func(a, b Usage) bool {
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			return a.PVC < b.PVC
+		}
+	default:
+		return fmt.Errorf("invalid sort %q (expected usage, used, size, name or namespace)", by)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool { return less(usages[i], usages[j]) })
+	return nil
+}
+
+// FilterByWorkload keeps usages whose workload matches the expression.
+// The expression is either Kind/Name, or a bare kind or name (case-insensitive).
+func FilterByWorkload(usages []Usage, expr string) []Usage {
+	if expr == "" {
+		return usages
+	}
+
+	var filtered []Usage
+	for _, u := range usages {
+		if matchWorkload(u.Workload, expr) {
+			filtered = append(filtered, u)
+		}
+	}
+	return filtered
+}
+
+// matchWorkload reports whether a Kind/Name workload matches the expression
+func matchWorkload(workload, expr string) bool {
+	if workload == "" {
+		return false
+	}
+	if strings.Contains(expr, "/") {
+		return strings.EqualFold(workload, expr)
+	}
+	kind, name, _ := strings.Cut(workload, "/")
+	return strings.EqualFold(kind, expr) || strings.EqualFold(name, expr)
+}
+
+// Group aggregates the usages that share a grouping key
+type Group struct {
+	Key            string  `json:"key"`
+	Usages         []Usage `json:"usages"`
+	CapacityBytes  int64   `json:"capacityBytes"`
+	UsedBytes      int64   `json:"usedBytes"`
+	AvailableBytes int64   `json:"availableBytes"`
+	PercentageUsed float64 `json:"percentageUsed"`
+}
+
+// GroupUsages groups usages by "namespace" or "workload", ordered by usage percentage (descending)
+func GroupUsages(usages []Usage, by string) ([]Group, error) {
+	var keyOf func(u Usage) string
+	switch by {
+	case "namespace":
+		keyOf = func(u Usage) string { return u.Namespace }
+	case "workload":
+		keyOf = func(u Usage) string {
+			if u.Workload == "" {
+				return "<none>"
+			}
+			// Workload names are only unique within a namespace
+			return u.Namespace + "/" + u.Workload
+		}
+	default:
+		return nil, fmt.Errorf("invalid group-by %q (expected namespace or workload)", by)
+	}
+
+	var groups []Group
+	index := make(map[string]int)
+	for _, u := range usages {
+		key := keyOf(u)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{Key: key})
+		}
+		g := &groups[i]
+		g.Usages = append(g.Usages, u)
+		g.CapacityBytes += u.CapacityBytes
+		g.UsedBytes += u.UsedBytes
+		g.AvailableBytes += u.AvailableBytes
+	}
+
+	for i := range groups {
+		if groups[i].CapacityBytes > 0 {
+			groups[i].PercentageUsed = float64(groups[i].UsedBytes) / float64(groups[i].CapacityBytes) * 100
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].PercentageUsed > groups[j].PercentageUsed
+	})
+
+	return groups, nil
+}
+
+// AttachSnapshots sets the snapshot statistics of each usage. PVCs without
+// snapshots get empty statistics rather than none.
+func AttachSnapshots(usages []Usage, stats map[k8s.ClaimKey]*k8s.SnapshotStats) {
+	for i := range usages {
+		if st, ok := stats[k8s.ClaimKey{Namespace: usages[i].Namespace, Name: usages[i].PVC}]; ok {
+			usages[i].Snapshots = st
+		} else {
+			usages[i].Snapshots = &k8s.SnapshotStats{}
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package pvc
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
 )
 
 func TestParseFilter(t *testing.T) {
@@ -96,3 +99,184 @@ func TestLimitTopN(t *testing.T) {
 		}
 	}
 }
+
+func TestSortUsages(t *testing.T) {
+	usages := []Usage{
+		{Namespace: "b", PVC: "small", CapacityBytes: 10, UsedBytes: 9, PercentageUsed: 90},
+		{Namespace: "a", PVC: "large", CapacityBytes: 100, UsedBytes: 50, PercentageUsed: 50},
+		{Namespace: "a", PVC: "empty", CapacityBytes: 50, UsedBytes: 0, PercentageUsed: 0},
+	}
+
+	tests := []struct {
+		by        string
+		wantFirst string
+	}{
+		{"", "small"},
+		{"usage", "small"},
+		{"used", "large"},
+		{"size", "large"},
+		{"name", "empty"},
+		{"namespace", "empty"},
+	}
+
+	for _, tt := range tests {
+		if err := SortUsages(usages, tt.by); err != nil {
+			t.Fatalf("SortUsages(%q) unexpected error: %v", tt.by, err)
+		}
+		if usages[0].PVC != tt.wantFirst {
+			t.Errorf("SortUsages(%q) first = %s, want %s", tt.by, usages[0].PVC, tt.wantFirst)
+		}
+	}
+
+	if err := SortUsages(usages, "node"); err == nil {
+		t.Errorf("SortUsages(\"node\") expected error")
+	}
+}
+
+func TestFilterByWorkload(t *testing.T) {
+	usages := []Usage{
+		{PVC: "data-kafka-0", Workload: "StatefulSet/kafka"},
+		{PVC: "data-kafka-1", Workload: "StatefulSet/kafka"},
+		{PVC: "uploads", Workload: "Deployment/web"},
+		{PVC: "orphan"},
+	}
+
+	tests := []struct {
+		expr       string
+		wantLength int
+	}{
+		{"StatefulSet/kafka", 2},
+		{"statefulset/kafka", 2},
+		{"kafka", 2},
+		{"Deployment", 1},
+		{"Deployment/kafka", 0},
+		{"", 4},
+	}
+
+	for _, tt := range tests {
+		got := FilterByWorkload(usages, tt.expr)
+		if len(got) != tt.wantLength {
+			t.Errorf("FilterByWorkload(%q) returned %d items, want %d", tt.expr, len(got), tt.wantLength)
+		}
+	}
+}
+
+func TestGroupUsages(t *testing.T) {
+	usages := []Usage{
+		{Namespace: "kafka", PVC: "data-0", Workload: "StatefulSet/kafka", CapacityBytes: 100, UsedBytes: 90},
+		{Namespace: "kafka", PVC: "data-1", Workload: "StatefulSet/kafka", CapacityBytes: 100, UsedBytes: 50},
+		{Namespace: "web", PVC: "uploads", Workload: "Deployment/web", CapacityBytes: 100, UsedBytes: 10},
+	}
+
+	groups, err := GroupUsages(usages, "workload")
+	if err != nil {
+		t.Fatalf("GroupUsages() unexpected error: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("GroupUsages() returned %d groups, want 2", len(groups))
+	}
+	if groups[0].Key != "kafka/StatefulSet/kafka" || len(groups[0].Usages) != 2 {
+		t.Errorf("GroupUsages() first group = %q with %d PVCs", groups[0].Key, len(groups[0].Usages))
+	}
+	if groups[0].UsedBytes != 140 || groups[0].PercentageUsed != 70 {
+		t.Errorf("GroupUsages() first group used = %d (%.0f%%), want 140 (70%%)", groups[0].UsedBytes, groups[0].PercentageUsed)
+	}
+
+	if _, err := GroupUsages(usages, "node"); err == nil {
+		t.Errorf("GroupUsages(\"node\") expected error")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(3)
+	for _, pct := range []float64{10, 20, 30, 40} {
+		h.Add([]Usage{
+			{Namespace: "ns", PVC: "a", PercentageUsed: pct},
+			{Namespace: "ns", PVC: "b", PercentageUsed: 1},
+		})
+	}
+
+	if got := h.Get("ns", "a"); len(got) != 3 || got[0] != 20 || got[2] != 40 {
+		t.Errorf("History.Get(a) = %v, want [20 30 40]", got)
+	}
+
+	// A PVC missing from a few refreshes, e.g. as its node couldn't be read,
+	// keeps its samples
+	for i := 0; i < historyGrace; i++ {
+		h.Add([]Usage{{Namespace: "ns", PVC: "a", PercentageUsed: 50}})
+	}
+	if got := h.Get("ns", "b"); len(got) != 3 {
+		t.Errorf("History.Get(b) = %v, want its samples kept for %d missing refreshes", got, historyGrace)
+	}
+	h.Add([]Usage{{Namespace: "ns", PVC: "b", PercentageUsed: 2}})
+	if got := h.Get("ns", "b"); len(got) != 3 || got[2] != 2 {
+		t.Errorf("History.Get(b) = %v, want [1 1 2] after it came back", got)
+	}
+
+	for i := 0; i <= historyGrace; i++ {
+		h.Add([]Usage{{Namespace: "ns", PVC: "a", PercentageUsed: 50}})
+	}
+	if got := h.Get("ns", "b"); got != nil {
+		t.Errorf("History.Get(b) = %v, want nil after %d missing refreshes", got, historyGrace+1)
+	}
+}
+
+func TestInlineUsage(t *testing.T) {
+	key := k8s.InlineVolumeKey{Namespace: "web", Pod: "api-7d9f-x2", Volume: "cache"}
+	workload := k8s.Workload{Kind: "Deployment", Name: "api"}
+	// The kubelet reports the node filesystem as the capacity of an emptyDir
+	vol := k8s.Volume{Name: "cache", CapacityBytes: 100 << 30, UsedBytes: 3 << 30, AvailableBytes: 60 << 30}
+
+	tests := []struct {
+		name   string
+		vol    k8s.Volume
+		inline k8s.InlineVolume
+		want   Usage
+		wantOK bool
+	}{
+		{
+			name:   "emptyDir without sizeLimit",
+			vol:    vol,
+			inline: k8s.InlineVolume{Type: k8s.InlineEmptyDir, Workload: workload},
+			want: Usage{Namespace: "web", PVC: "api-7d9f-x2/cache", Type: VolumeTypeEmptyDir,
+				CapacityBytes: 100 << 30, UsedBytes: 3 << 30, AvailableBytes: 60 << 30, PercentageUsed: 3,
+				Pods: []string{"api-7d9f-x2"}, Workload: "Deployment/api"},
+			wantOK: true,
+		},
+		{
+			name:   "emptyDir with sizeLimit",
+			vol:    vol,
+			inline: k8s.InlineVolume{Type: k8s.InlineEmptyDir, SizeLimitBytes: 4 << 30, Workload: workload},
+			want: Usage{Namespace: "web", PVC: "api-7d9f-x2/cache", Type: VolumeTypeEmptyDir,
+				CapacityBytes: 4 << 30, UsedBytes: 3 << 30, AvailableBytes: 1 << 30, PercentageUsed: 75,
+				Pods: []string{"api-7d9f-x2"}, Workload: "Deployment/api"},
+			wantOK: true,
+		},
+		{
+			name:   "emptyDir over its sizeLimit",
+			vol:    vol,
+			inline: k8s.InlineVolume{Type: k8s.InlineEmptyDir, SizeLimitBytes: 2 << 30},
+			want: Usage{Namespace: "web", PVC: "api-7d9f-x2/cache", Type: VolumeTypeEmptyDir,
+				CapacityBytes: 2 << 30, UsedBytes: 3 << 30, AvailableBytes: 0, PercentageUsed: 150,
+				Pods: []string{"api-7d9f-x2"}},
+			wantOK: true,
+		},
+		{
+			name:   "CSI volume without stats",
+			vol:    k8s.Volume{Name: "cache"},
+			inline: k8s.InlineVolume{Type: k8s.InlineCSI},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := inlineUsage(key, tt.vol, tt.inline)
+		if ok != tt.wantOK {
+			t.Errorf("%s: inlineUsage() ok = %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: inlineUsage() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+//go:embed dashboard.html
+var dashboard []byte
+
+// Server serves PVC usage over HTTP from a snapshot refreshed in the background
+type Server struct {
+	client     *k8s.Client
+	opts       pvc.Options
+	interval   time.Duration
+	thresholds pvc.Thresholds
+
+	mu      sync.RWMutex
+	usages  []pvc.Usage
+	history *pvc.History
+	updated time.Time
+	lastErr error
+}
+
+// New creates a server that refreshes usage every interval and keeps
+// historySize samples per PVC
+func New(client *k8s.Client, opts pvc.Options, interval time.Duration, historySize int) *Server {
+	return &Server{
+		client:     client,
+		opts:       opts,
+		interval:   interval,
+		thresholds: pvc.Thresholds{Warn: 80, Crit: 90},
+		history:    pvc.NewHistory(historySize),
+	}
+}
+
+// SetThresholds sets the usage percentages at which the dashboard shows PVCs
+// as warning and critical
+func (s *Server) SetThresholds(thresholds pvc.Thresholds) {
+	s.thresholds = thresholds
+}
+
+// ListenAndServe collects usage once, then serves HTTP on addr while refreshing
+// usage in the background
+func (s *Server) ListenAndServe(addr string) error {
+	s.refresh()
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.refresh()
+		}
+	}()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	log.Printf("Serving PVC usage on http://%s", addr)
+	return srv.ListenAndServe()
+}
+
+// refresh collects usage from the cluster and replaces the snapshot
+func (s *Server) refresh() {
+	usages, err := pvc.GetUsages(s.client, s.opts)
+	if err != nil {
+		log.Printf("Error getting PVC usages: %v", err)
+		s.mu.Lock()
+		s.lastErr = err
+		s.mu.Unlock()
+		return
+	}
+	s.update(usages, time.Now())
+}
+
+// update replaces the snapshot and records it in the history
+func (s *Server) update(usages []pvc.Usage, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usages = usages
+	s.history.Add(usages)
+	s.updated = now
+	s.lastErr = nil
+}
+
+// Handler returns the HTTP routes of the API and the dashboard
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/usages", s.handleUsages)
+	mux.HandleFunc("GET /api/v1/pvcs/{namespace}/{name}", s.handlePVC)
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboard)
+	})
+	return mux
+}
+
+// usagesResponse is the body of GET /api/v1/usages
+type usagesResponse struct {
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	Error      string         `json:"error,omitempty"` // last refresh error, the data is stale
+	Thresholds pvc.Thresholds `json:"thresholds"`      // of the warning and critical states
+	Usages     []pvc.Usage    `json:"usages,omitempty"`
+	Groups     []pvc.Group    `json:"groups,omitempty"`
+}
+
+// handleUsages serves the usage snapshot. It accepts the same query parameters
+// as the CLI flags: namespace, workload, filter, sort, top and group-by.
+func (s *Server) handleUsages(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	usages := append([]pvc.Usage(nil), s.usages...)
+	response := usagesResponse{UpdatedAt: s.updated, Thresholds: s.thresholds}
+	if s.lastErr != nil {
+		response.Error = s.lastErr.Error()
+	}
+	s.mu.RUnlock()
+
+	query := r.URL.Query()
+	usages, groups, err := selectUsages(usages, query.Get("namespace"), query.Get("workload"),
+		query.Get("filter"), query.Get("sort"), query.Get("top"), query.Get("group-by"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if groups != nil {
+		response.Groups = groups
+	} else {
+		response.Usages = usages
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// selectUsages applies the CLI filters, sort order, top N and grouping to usages
+func selectUsages(usages []pvc.Usage, namespace, workload, filter, sortBy, top, groupBy string) ([]pvc.Usage, []pvc.Group, error) {
+	if namespace != "" {
+		var namespaceFiltered []pvc.Usage
+		for _, u := range usages {
+			if u.Namespace == namespace {
+				namespaceFiltered = append(namespaceFiltered, u)
+			}
+		}
+		usages = namespaceFiltered
+	}
+
+	usages = pvc.FilterByWorkload(usages, workload)
+
+	usages, err := pvc.FilterUsages(usages, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := pvc.SortUsages(usages, sortBy); err != nil {
+		return nil, nil, err
+	}
+
+	if top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 0 {
+			return nil, nil, fmt.Errorf("invalid top %q", top)
+		}
+		usages = pvc.LimitTopN(usages, n)
+	}
+
+	if groupBy != "" {
+		groups, err := pvc.GroupUsages(usages, groupBy)
+		if err != nil {
+			return nil, nil, err
+		}
+		return usages, groups, nil
+	}
+
+	return display.EmptyIfNil(usages), nil, nil
+}
+
+// pvcResponse is the body of GET /api/v1/pvcs/{namespace}/{name}
+type pvcResponse struct {
+	pvc.Usage
+	// History holds the usage percentage of each refresh, oldest first
+	History         []float64 `json:"history"`
+	IntervalSeconds float64   `json:"intervalSeconds"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// handlePVC serves the current usage of one PVC with its history
+func (s *Server) handlePVC(w http.ResponseWriter, r *http.Request) {
+	namespace, name := r.PathValue("namespace"), r.PathValue("name")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.usages {
+		if u.Namespace == namespace && u.PVC == name {
+			writeJSON(w, http.StatusOK, pvcResponse{
+				Usage:           u,
+				History:         append([]float64(nil), s.history.Get(namespace, name)...),
+				IntervalSeconds: s.interval.Seconds(),
+				UpdatedAt:       s.updated,
+			})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("PVC '%s' not found in namespace '%s'", name, namespace))
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// writeError writes an error as a JSON response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
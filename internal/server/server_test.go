@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+func testServer() *Server {
+	s := New(nil, pvc.Options{}, 30*time.Second, 10)
+	s.update([]pvc.Usage{
+		{Namespace: "kafka", PVC: "data-0", Workload: "StatefulSet/kafka", CapacityBytes: 100, UsedBytes: 40, PercentageUsed: 40},
+		{Namespace: "web", PVC: "uploads", Workload: "Deployment/web", CapacityBytes: 100, UsedBytes: 10, PercentageUsed: 10},
+	}, time.Now())
+	s.update([]pvc.Usage{
+		{Namespace: "kafka", PVC: "data-0", Workload: "StatefulSet/kafka", CapacityBytes: 100, UsedBytes: 90, PercentageUsed: 90},
+		{Namespace: "web", PVC: "uploads", Workload: "Deployment/web", CapacityBytes: 100, UsedBytes: 10, PercentageUsed: 10},
+	}, time.Now())
+	return s
+}
+
+func TestHandleUsages(t *testing.T) {
+	handler := testServer().Handler()
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantPVCs   []string
+		wantGroups int
+	}{
+		{"", http.StatusOK, []string{"data-0", "uploads"}, 0},
+		{"?sort=name", http.StatusOK, []string{"data-0", "uploads"}, 0},
+		{"?filter=<50", http.StatusOK, []string{"uploads"}, 0},
+		{"?namespace=kafka", http.StatusOK, []string{"data-0"}, 0},
+		{"?workload=Deployment", http.StatusOK, []string{"uploads"}, 0},
+		{"?top=1", http.StatusOK, []string{"data-0"}, 0},
+		{"?filter=>95", http.StatusOK, []string{}, 0},
+		{"?group-by=namespace", http.StatusOK, nil, 2},
+		{"?filter=bad", http.StatusBadRequest, nil, 0},
+		{"?sort=node", http.StatusBadRequest, nil, 0},
+		{"?top=x", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/usages"+tt.query, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("GET usages%s status = %d, want %d", tt.query, rec.Code, tt.wantStatus)
+			continue
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		var body usagesResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET usages%s returned invalid JSON: %v", tt.query, err)
+		}
+		if len(body.Groups) != tt.wantGroups {
+			t.Errorf("GET usages%s returned %d groups, want %d", tt.query, len(body.Groups), tt.wantGroups)
+		}
+		if tt.wantPVCs == nil {
+			continue
+		}
+		if len(body.Usages) != len(tt.wantPVCs) {
+			t.Errorf("GET usages%s returned %d PVCs, want %d", tt.query, len(body.Usages), len(tt.wantPVCs))
+			continue
+		}
+		for i, name := range tt.wantPVCs {
+			if body.Usages[i].PVC != name {
+				t.Errorf("GET usages%s PVC %d = %s, want %s", tt.query, i, body.Usages[i].PVC, name)
+			}
+		}
+	}
+}
+
+func TestHandlePVC(t *testing.T) {
+	handler := testServer().Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/pvcs/kafka/data-0", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET pvc status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var body pvcResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET pvc returned invalid JSON: %v", err)
+	}
+	if body.PVC != "data-0" || body.PercentageUsed != 90 {
+		t.Errorf("GET pvc = %s at %.0f%%, want data-0 at 90%%", body.PVC, body.PercentageUsed)
+	}
+	if len(body.History) != 2 || body.History[0] != 40 || body.History[1] != 90 {
+		t.Errorf("GET pvc history = %v, want [40 90]", body.History)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/pvcs/kafka/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET missing pvc status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleUsagesThresholds(t *testing.T) {
+	s := testServer()
+	s.SetThresholds(pvc.Thresholds{Warn: 70, Crit: 85})
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/usages", nil))
+	var body struct {
+		Thresholds map[string]float64 `json:"thresholds"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET usages returned invalid JSON: %v", err)
+	}
+	if body.Thresholds["warn"] != 70 || body.Thresholds["crit"] != 85 {
+		t.Errorf("thresholds = %v, want warn 70 and crit 85", body.Thresholds)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// newNodesCmd builds the nodes command: node filesystem and imagefs usage,
+// DiskPressure and the pods using the most ephemeral storage
+func newNodesCmd(o *cliOptions) *cobra.Command {
+	var output string
+	var nodeSelector string
+	var topPods int
+
+	cmd := &cobra.Command{
+		Use:   "nodes",
+		Short: "Show node filesystem and imagefs usage, DiskPressure and the top ephemeral-storage pods",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "json", "yaml"); err != nil {
+				return err
+			}
+			if topPods < 0 {
+				return fmt.Errorf("--top-pods must not be negative")
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			// Node disk usage is about every pod on the node, so unlike the
+			// other commands the pods are only limited to a namespace given with -n
+			namespace := ""
+			if !o.allNamespaces && o.configFlags.Namespace != nil {
+				namespace = *o.configFlags.Namespace
+			}
+
+			nodes, err := pvc.GetNodeUsages(client, nodeSelector, namespace, topPods)
+			if err != nil {
+				return err
+			}
+
+			if output != "table" {
+				return display.PrintStructured(output, display.EmptyIfNil(nodes))
+			}
+			display.ShowNodes(nodes, namespace)
+			return nil
+		},
+	}
+	registerOutputFlag(cmd, &output, "table", "json", "yaml")
+	cmd.Flags().StringVar(&nodeSelector, "node-selector", "", "Only show nodes matching this label selector (e.g. 'role=storage')")
+	cmd.Flags().IntVar(&topPods, "top-pods", 5, "Number of pods using the most ephemeral storage to show per node (0 shows all)")
+	return cmd
+}
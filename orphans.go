@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+)
+
+// newOrphansCmd builds the orphans command: PVCs that no running pod mounts
+func newOrphansCmd(o *cliOptions) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "List PVCs that no running pod mounts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "json", "yaml"); err != nil {
+				return err
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.listNamespace()
+			if err != nil {
+				return err
+			}
+
+			orphans, err := client.GetOrphanClaims(namespace)
+			if err != nil {
+				return err
+			}
+			if output != "table" {
+				return display.PrintStructured(output, display.EmptyIfNil(orphans))
+			}
+			display.ShowOrphans(orphans)
+			return nil
+		},
+	}
+	registerOutputFlag(cmd, &output, "table", "json", "yaml")
+	return cmd
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/perf"
+)
+
+// newPerfCmd builds the perf command and its replay and summary subcommands
+func newPerfCmd(o *cliOptions) *cobra.Command {
+	var selector, record string
+	var pvcFlags []string
+	var ephemeral, guessPod bool
+	var podFlags *podSettingsFlags
+
+	cmd := &cobra.Command{
+		Use:   "perf [PVC...]",
+		Short: "Monitor the performance of one PVC, or compare several side by side",
+		Example: `  pvcusage perf data-kafka-0 -n kafka
+  pvcusage perf data-kafka-0 data-kafka-1 -n kafka
+  pvcusage perf -l app=kafka -n kafka
+  pvcusage perf --pvc replay -n kafka   # a PVC named like a subcommand`,
+		ValidArgsFunction: completePVCs(o),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && len(pvcFlags) == 0 && selector == "" {
+				return fmt.Errorf("specify PVC names or a --selector")
+			}
+			if o.allNamespaces {
+				return fmt.Errorf("-A is not supported by perf, use -n")
+			}
+			// A profile's perf settings replace the defaults
+			base := k8s.DefaultPodSettings()
+			if o.profile != nil && o.profile.PerfSettings() != nil {
+				base = *o.profile.PerfSettings()
+			}
+			podSettings, err := podFlags.settings(base)
+			if err != nil {
+				return err
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.namespace()
+			if err != nil {
+				return err
+			}
+
+			pvcNames := append(append([]string{}, args...), pvcFlags...)
+			if selector != "" {
+				selected, err := client.ListPVCNames(namespace, selector)
+				if err != nil {
+					return err
+				}
+				pvcNames = append(pvcNames, selected...)
+			}
+			// A PVC named and also matched by the selector is monitored once
+			pvcNames = uniqueNames(pvcNames)
+			if len(pvcNames) == 0 {
+				return fmt.Errorf("no PVC matches selector '%s' in namespace '%s'", selector, namespace)
+			}
+
+			// Several PVCs are compared side by side instead
+			if len(pvcNames) > 1 {
+				if record != "" {
+					return fmt.Errorf("--record supports a single PVC")
+				}
+				return runPerfDashboard(client, namespace, pvcNames, podSettings, ephemeral, guessPod)
+			}
+			return runPerfMonitor(client, namespace, pvcNames[0], podSettings, record, ephemeral, guessPod)
+		},
+	}
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Compare every PVC in the namespace matching this label selector")
+	cmd.Flags().StringSliceVar(&pvcFlags, "pvc", nil, "PVC to monitor, for PVCs named like a subcommand (replay, summary); can be repeated")
+	cmd.Flags().StringVar(&record, "record", "", "Record every metrics sample to this JSON Lines file")
+	cmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "Inject the monitor into the consumer pod as an ephemeral container instead of creating a pod")
+	cmd.Flags().BoolVar(&guessPod, "guess-pod", false, "If no pod uses the PVC, guess one from naming patterns instead of failing")
+	podFlags = registerPodSettingsFlags(cmd.Flags())
+
+	cmd.RegisterFlagCompletionFunc("pvc", completePVCs(o))
+
+	cmd.AddCommand(newPerfReplayCmd(), newPerfSummaryCmd())
+	return cmd
+}
+
+// runPerfMonitor shows the live performance of one PVC until interrupted
+func runPerfMonitor(client *k8s.Client, namespace, pvcName string, settings k8s.PodSettings, record string, ephemeral, guessPod bool) error {
+	log.Printf("Starting performance analysis for PVC '%s' in namespace '%s'...", pvcName, namespace)
+
+	// Find pod that uses this PVC
+	pod, err := client.FindPodUsingPVC(namespace, pvcName, guessPod)
+	if errors.Is(err, k8s.ErrNoConsumer) {
+		// Unmounted volumes are monitored from wherever the PersistentVolume is reachable
+		log.Printf("No pod uses the PVC, scheduling the monitor using the volume's placement")
+	} else if err != nil {
+		return fmt.Errorf("error finding pod using PVC: %v", err)
+	} else {
+		log.Printf("Found pod '%s' using the PVC", pod)
+	}
+
+	// Open the recording before creating any pod, so a bad path doesn't leak one
+	var recorder *perf.Recorder
+	if record != "" {
+		recorder, err = perf.NewRecorder(record)
+		if err != nil {
+			return err
+		}
+		defer recorder.Close()
+	}
+
+	// Setup signal handling before creating the monitor, so that an interrupt
+	// during startup still deletes it
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	// Start performance monitoring
+	perfMonitor, err := perf.StartMonitoring(client, namespace, pod, pvcName, settings, ephemeral)
+	if err != nil {
+		return fmt.Errorf("error starting performance monitoring: %v", err)
+	}
+
+	if recorder != nil {
+		perfMonitor.SetRecorder(recorder)
+	}
+
+	// Show performance metrics in real-time
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			display.ClearScreen()
+			display.ShowPerfMetrics(perfMonitor.GetLatestMetrics(), perfMonitor.GetHistory())
+		case <-sigs:
+			fmt.Println("\nStopping performance monitoring...")
+			perfMonitor.Stop()
+			return nil
+		}
+	}
+}
+
+// newPerfReplayCmd builds the perf replay command: play a recording through the live perf screen
+func newPerfReplayCmd() *cobra.Command {
+	var speed float64
+
+	cmd := &cobra.Command{
+		Use:   "replay RECORDING",
+		Short: "Play a recorded session through the live perf screen",
+		Args:  recordingArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if speed <= 0 {
+				return fmt.Errorf("--speed must be greater than 0")
+			}
+			samples, err := readRecording(args[0])
+			if err != nil {
+				return err
+			}
+
+			for i, sample := range samples {
+				if i > 0 {
+					gap := sample.Timestamp.Sub(samples[i-1].Timestamp)
+					time.Sleep(time.Duration(float64(gap) / speed))
+				}
+				start := i + 1 - perf.HistorySize
+				if start < 0 {
+					start = 0
+				}
+				display.ClearScreen()
+				display.ShowPerfMetrics(sample, samples[start:i+1])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Float64Var(&speed, "speed", 1, "Playback speed multiplier (e.g. 10 plays ten times faster)")
+	return cmd
+}
+
+// newPerfSummaryCmd builds the perf summary command: min/avg/max/p95 per metric of a recording
+func newPerfSummaryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "summary RECORDING",
+		Short: "Print min/avg/max/p95 of each metric of a recorded session",
+		Args:  recordingArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			samples, err := readRecording(args[0])
+			if err != nil {
+				return err
+			}
+			display.ShowPerfSummary(samples, perf.Summarize(samples))
+			return nil
+		},
+	}
+}
+
+// recordingArg requires the single recording argument of replay and summary.
+// Without it, `perf replay` was most likely meant to monitor a PVC of that name,
+// which cobra resolves to the subcommand, so the error points at --pvc.
+func recordingArg(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s takes one recording file, got %d arguments; to monitor a PVC named %q use: perf --pvc %s",
+			cmd.CommandPath(), len(args), cmd.Name(), cmd.Name())
+	}
+	return nil
+}
+
+// readRecording reads a recording, failing if it has no samples
+func readRecording(path string) ([]perf.Metrics, error) {
+	samples, err := perf.ReadRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("recording %s is empty", path)
+	}
+	return samples, nil
+}
+
+// runPerfDashboard monitors several PVCs concurrently and shows them side by side.
+// It fails if no monitor could be started.
+func runPerfDashboard(client *k8s.Client, namespace string, pvcNames []string, settings k8s.PodSettings, ephemeral, guessPod bool) error {
+	log.Printf("Starting performance analysis for %d PVCs in namespace '%s'...", len(pvcNames), namespace)
+
+	// Setup signal handling first, so that an interrupt during startup still
+	// deletes the monitors created so far
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	monitors := make([]*perf.Monitor, len(pvcNames))
+	var wg sync.WaitGroup
+	for i, name := range pvcNames {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			pod, err := client.FindPodUsingPVC(namespace, name, guessPod)
+			if err != nil && !errors.Is(err, k8s.ErrNoConsumer) {
+				log.Printf("Skipping PVC '%s': %v", name, err)
+				return
+			}
+
+			monitor, err := perf.StartMonitoring(client, namespace, pod, name, settings, ephemeral)
+			if err != nil {
+				log.Printf("Skipping PVC '%s': %v", name, err)
+				return
+			}
+			monitors[i] = monitor
+		}(i, name)
+	}
+
+	stopAll := func() {
+		for _, m := range monitors {
+			if m != nil {
+				m.Stop()
+			}
+		}
+	}
+
+	started := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(started)
+	}()
+	select {
+	case <-started:
+	case <-sigs:
+		fmt.Println("\nStopping performance monitoring, waiting for the monitors being started...")
+		<-started
+		stopAll()
+		return nil
+	}
+
+	running := 0
+	for _, m := range monitors {
+		if m != nil {
+			running++
+		}
+	}
+	if running == 0 {
+		return fmt.Errorf("could not start monitoring any of the %d PVCs", len(pvcNames))
+	}
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			var rows []display.PerfRow
+			for i, m := range monitors {
+				if m != nil {
+					rows = append(rows, display.PerfRow{PVC: pvcNames[i], Metrics: m.GetLatestMetrics()})
+				}
+			}
+			display.ClearScreen()
+			display.ShowPerfComparison(rows)
+		case <-sigs:
+			fmt.Println("\nStopping performance monitoring...")
+			stopAll()
+			return nil
+		}
+	}
+}
+
+// uniqueNames removes repeated names, keeping the first occurrence of each
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var unique []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+// podSettingsFlags holds the flags that configure the performance monitor pod
+type podSettingsFlags struct {
+	fs                 *pflag.FlagSet
+	configFile         *string
+	image              *string
+	imagePullSecrets   *string
+	tolerations        *string
+	priorityClassName  *string
+	serviceAccountName *string
+	cpuRequest         *string
+	cpuLimit           *string
+	memoryRequest      *string
+	memoryLimit        *string
+	runAsNonRoot       *bool
+	runAsUser          *int64
+	seccompProfile     *string
+	activeDeadline     *int64
+}
+
+// registerPodSettingsFlags defines the monitor pod flags on fs
+func registerPodSettingsFlags(fs *pflag.FlagSet) *podSettingsFlags {
+	defaults := k8s.DefaultPodSettings()
+	return &podSettingsFlags{
+		fs:                 fs,
+		configFile:         fs.String("pod-config", "", "YAML file with monitor pod settings; flags below override it"),
+		image:              fs.String("image", defaults.Image, "Image of the performance monitor pod"),
+		imagePullSecrets:   fs.String("pull-secrets", "", "Comma-separated imagePullSecrets for the monitor pod"),
+		tolerations:        fs.String("tolerations", "", "Comma-separated tolerations for the monitor pod (key[=value]:effect)"),
+		priorityClassName:  fs.String("priority-class", "", "PriorityClass of the monitor pod"),
+		serviceAccountName: fs.String("service-account", "", "ServiceAccount of the monitor pod"),
+		cpuRequest:         fs.String("cpu-request", defaults.CPURequest, "CPU request of the monitor pod"),
+		cpuLimit:           fs.String("cpu-limit", defaults.CPULimit, "CPU limit of the monitor pod"),
+		memoryRequest:      fs.String("memory-request", defaults.MemoryRequest, "Memory request of the monitor pod"),
+		memoryLimit:        fs.String("memory-limit", defaults.MemoryLimit, "Memory limit of the monitor pod"),
+		runAsNonRoot:       fs.Bool("run-as-non-root", defaults.RunAsNonRoot, "Run the monitor pod as a non-root user"),
+		runAsUser:          fs.Int64("run-as-user", defaults.RunAsUser, "UID the monitor pod runs as (0 keeps the image default)"),
+		seccompProfile:     fs.String("seccomp", defaults.SeccompProfile, "Seccomp profile: RuntimeDefault, Unconfined or Localhost/<path>"),
+		activeDeadline:     fs.Int64("active-deadline", defaults.ActiveDeadlineSeconds, "Seconds after which the monitor pod stops even if it was never deleted (0 disables)"),
+	}
+}
+
+// settings builds the pod settings from base, e.g. a profile's, then the
+// --pod-config file if given, then the flags that were set
+func (f *podSettingsFlags) settings(base k8s.PodSettings) (k8s.PodSettings, error) {
+	settings := base
+	if *f.configFile != "" {
+		var err error
+		settings, err = k8s.LoadPodSettings(*f.configFile, base)
+		if err != nil {
+			return settings, err
+		}
+	}
+
+	var err error
+	f.fs.Visit(func(fl *pflag.Flag) {
+		switch fl.Name {
+		case "image":
+			settings.Image = *f.image
+		case "pull-secrets":
+			settings.ImagePullSecrets = splitList(*f.imagePullSecrets)
+		case "tolerations":
+			settings.Tolerations = nil
+			for _, t := range splitList(*f.tolerations) {
+				toleration, parseErr := k8s.ParseToleration(t)
+				if parseErr != nil {
+					err = parseErr
+					return
+				}
+				settings.Tolerations = append(settings.Tolerations, toleration)
+			}
+		case "priority-class":
+			settings.PriorityClassName = *f.priorityClassName
+		case "service-account":
+			settings.ServiceAccountName = *f.serviceAccountName
+		case "cpu-request":
+			settings.CPURequest = *f.cpuRequest
+		case "cpu-limit":
+			settings.CPULimit = *f.cpuLimit
+		case "memory-request":
+			settings.MemoryRequest = *f.memoryRequest
+		case "memory-limit":
+			settings.MemoryLimit = *f.memoryLimit
+		case "run-as-non-root":
+			settings.RunAsNonRoot = *f.runAsNonRoot
+		case "run-as-user":
+			settings.RunAsUser = *f.runAsUser
+		case "seccomp":
+			settings.SeccompProfile = *f.seccompProfile
+		case "active-deadline":
+			settings.ActiveDeadlineSeconds = *f.activeDeadline
+		}
+	})
+	if err != nil {
+		return settings, fmt.Errorf("invalid --tolerations: %v", err)
+	}
+	return settings, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// newQuotaCmd builds the quota command: storage quotas per namespace against
+// the PVC requests and used space
+func newQuotaCmd(o *cliOptions) *cobra.Command {
+	var opts pvc.Options
+	var output string
+	var policy pvc.ExpansionPolicy
+	var blockedOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "quota",
+		Short: "Compare namespace storage quotas with PVC requests and flag blocked expansions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "json", "yaml"); err != nil {
+				return err
+			}
+			if policy.ExpandBy <= 0 {
+				return fmt.Errorf("--expand-by must be positive")
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.listNamespace()
+			if err != nil {
+				return err
+			}
+			opts.Namespace = namespace
+
+			quotas, maxPVCBytes, err := client.GetStorageQuotas(namespace)
+			if err != nil {
+				return err
+			}
+			sizes, err := client.GetClaimSizes(namespace)
+			if err != nil {
+				return err
+			}
+			usages, err := pvc.GetUsages(client, opts)
+			if err != nil {
+				return err
+			}
+
+			namespaces := pvc.EvaluateQuotas(quotas, maxPVCBytes, sizes, usages, policy)
+			if blockedOnly {
+				var blocked []pvc.NamespaceQuota
+				for _, n := range namespaces {
+					if len(n.Blocked) > 0 {
+						blocked = append(blocked, n)
+					}
+				}
+				namespaces = blocked
+			}
+
+			if output != "table" {
+				return display.PrintStructured(output, display.EmptyIfNil(namespaces))
+			}
+			display.ShowQuotas(namespaces, policy.ExpandBy)
+			return nil
+		},
+	}
+	registerOutputFlag(cmd, &output, "table", "json", "yaml")
+	cmd.Flags().Float64Var(&policy.NearlyFull, "warn", 80, "Usage percentage from which a PVC is checked for expansion")
+	cmd.Flags().Float64Var(&policy.ExpandBy, "expand-by", 50, "Expansion to check, in percent of the PVC's current request")
+	cmd.Flags().BoolVar(&blockedOnly, "blocked", false, "Only show namespaces where an expansion would be blocked")
+	cmd.Flags().StringVar(&opts.NodeSelector, "node-selector", "", "Only collect used space from nodes matching this label selector")
+	return cmd
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// newRecommendCmd builds the recommend command: right-sizing of PVCs from
+// their usage history
+func newRecommendCmd(o *cliOptions) *cobra.Command {
+	var opts pvc.Options
+	var output string
+	var historyPath string
+	var window time.Duration
+	var policy pvc.Policy
+	var round string
+
+	cmd := &cobra.Command{
+		Use:   "recommend",
+		Short: "Suggest PVCs to grow or shrink from their usage history",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "json", "yaml", "patch"); err != nil {
+				return err
+			}
+			if policy.Percentile <= 0 || policy.Percentile > 100 {
+				return fmt.Errorf("--percentile must be between 0 and 100")
+			}
+			if policy.Headroom < 0 {
+				return fmt.Errorf("--headroom must not be negative")
+			}
+			quantity, err := resource.ParseQuantity(round)
+			if err != nil || quantity.Value() <= 0 {
+				return fmt.Errorf("invalid --round %q, expected a positive size such as 10Gi", round)
+			}
+			policy.RoundTo = quantity.Value()
+
+			now := time.Now()
+			var history map[k8s.ClaimKey][]pvc.UsageSample
+			if historyPath != "" {
+				if history, err = pvc.ReadEventLog(historyPath, now.Add(-window)); err != nil {
+					return err
+				}
+			}
+
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.listNamespace()
+			if err != nil {
+				return err
+			}
+			opts.Namespace = namespace
+			sizes, err := client.GetClaimSizes(namespace)
+			if err != nil {
+				return err
+			}
+			usages, err := pvc.GetUsages(client, opts)
+			if err != nil {
+				return err
+			}
+
+			recommendations := pvc.Recommend(sizes, usages, history, policy, now)
+			expandable, err := client.GetExpandableStorageClasses()
+			if err != nil {
+				log.Printf("Warning: could not check which StorageClasses allow expansion, assuming all do: %v", err)
+			}
+			for i := range recommendations {
+				recommendations[i].Expandable = expandable == nil || expandable[recommendations[i].StorageClass]
+			}
+
+			switch output {
+			case "patch":
+				return display.PrintExpansionPatches(recommendations)
+			case "json", "yaml":
+				return display.PrintStructured(output, display.EmptyIfNil(recommendations))
+			}
+			display.ShowRecommendations(recommendations)
+			return nil
+		},
+	}
+	registerOutputFlag(cmd, &output, "table", "json", "yaml", "patch")
+	cmd.Flags().StringVar(&historyPath, "history", "", "Event log written by 'watch -o events --event-min-change 0' to size PVCs from (default: the current usage)")
+	cmd.Flags().DurationVar(&window, "window", 14*24*time.Hour, "Period of the history to consider")
+	cmd.Flags().Float64Var(&policy.Percentile, "percentile", 95, "Percentile of the used space over the window to size for")
+	cmd.Flags().Float64Var(&policy.Headroom, "headroom", 30, "Percentage of free space to add on top of the percentile")
+	cmd.Flags().StringVar(&round, "round", "1Gi", "Round target sizes up to a multiple of this size")
+	cmd.Flags().StringVar(&opts.NodeSelector, "node-selector", "", "Only collect usage from nodes matching this label selector")
+	return cmd
+}
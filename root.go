@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/joseEnrique/pvcusage/internal/config"
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+)
+
+// cliOptions holds the kubectl-style flags shared by every command
+type cliOptions struct {
+	configFlags   *genericclioptions.ConfigFlags
+	allNamespaces bool
+	configPath    string
+	profileName   string
+	config        *config.Config  // nil when there is no configuration file
+	profile       *config.Profile // nil when no profile is selected
+}
+
+// client creates a Kubernetes client from the kubeconfig and flags
+func (o *cliOptions) client() (*k8s.Client, error) {
+	config, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error building kubeconfig: %v", err)
+	}
+	return k8s.NewClient(config)
+}
+
+// namespace returns the namespace from -n or the kubeconfig context
+func (o *cliOptions) namespace() (string, error) {
+	namespace, _, err := o.configFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("error getting namespace: %v", err)
+	}
+	return namespace, nil
+}
+
+// listNamespace returns the namespace to list in, or "" with -A for all namespaces
+func (o *cliOptions) listNamespace() (string, error) {
+	if o.allNamespaces {
+		return "", nil
+	}
+	return o.namespace()
+}
+
+// loadProfile reads the configuration file and applies the selected profile
+// to the flags of cmd that weren't given on the command line
+func (o *cliOptions) loadProfile(cmd *cobra.Command) error {
+	if _, err := os.Stat(o.configPath); os.IsNotExist(err) {
+		if o.profileName != "" {
+			return fmt.Errorf("profile %q requested but %s does not exist", o.profileName, o.configPath)
+		}
+		return nil
+	}
+
+	cfg, err := config.Load(o.configPath)
+	if err != nil {
+		return err
+	}
+	o.config = cfg
+	profile, err := cfg.Profile(o.profileName)
+	if err != nil || profile == nil {
+		return err
+	}
+
+	flags := profile.Flags()
+	output := ""
+	switch cmd.Name() {
+	case "usage":
+		output = profile.Output
+	case "watch":
+		output = profile.WatchOutput
+	}
+	if output != "" {
+		flags["output"] = output
+	}
+
+	for name, value := range flags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("profile sets invalid %s %q: %v", name, value, err)
+		}
+	}
+	o.profile = profile
+	return nil
+}
+
+// newRootCmd builds the command tree
+func newRootCmd() *cobra.Command {
+	o := &cliOptions{configFlags: genericclioptions.NewConfigFlags(true)}
+
+	root := &cobra.Command{
+		Use:           "pvcusage",
+		Short:         "Monitor Persistent Volume Claim usage and performance",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Annotations:   map[string]string{cobra.CommandDisplayNameAnnotation: commandName()},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Completion must keep working with a broken configuration file
+			if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+				return nil
+			}
+			return o.loadProfile(cmd)
+		},
+	}
+	o.configFlags.AddFlags(root.PersistentFlags())
+	root.PersistentFlags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", false, "List PVCs across all namespaces")
+	root.PersistentFlags().StringVar(&o.configPath, "config", config.DefaultPath(), "Configuration file with profiles")
+	root.PersistentFlags().StringVar(&o.profileName, "profile", "", "Profile of the configuration file to use (default: its defaultProfile)")
+	root.RegisterFlagCompletionFunc("namespace", completeNamespaces(o))
+
+	root.AddCommand(
+		newUsageCmd(o),
+		newWatchCmd(o),
+		newPerfCmd(o),
+		newNodesCmd(o),
+		newOrphansCmd(o),
+		newSnapshotsCmd(o),
+		newCapacityCmd(o),
+		newQuotaCmd(o),
+		newCostCmd(o),
+		newRecommendCmd(o),
+		newBenchCmd(o),
+		newCleanupCmd(o),
+		newServeCmd(o),
+	)
+	return root
+}
+
+// commandName returns "kubectl pvcusage" when running as a kubectl plugin
+func commandName() string {
+	if strings.HasPrefix(filepath.Base(os.Args[0]), "kubectl-") {
+		return "kubectl pvcusage"
+	}
+	return "pvcusage"
+}
+
+// defaultToUsage runs the usage command when no subcommand is given, so that
+// a bare `kubectl pvcusage -A` shows the usage table
+func defaultToUsage(root *cobra.Command, args []string) []string {
+	cmd, _, err := root.Find(args)
+	if err != nil || cmd != root {
+		return args
+	}
+	for _, arg := range args {
+		if arg == "-h" || arg == "--help" || arg == "help" {
+			return args
+		}
+	}
+	return append([]string{"usage"}, args...)
+}
+
+// completeNamespaces completes namespace names from the cluster
+func completeNamespaces(o *cliOptions) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		client, err := o.client()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, err := client.ListNamespaceNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completePVCs completes PVC names in the selected namespace, skipping those already given
+func completePVCs(o *cliOptions) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		client, err := o.client()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		namespace, err := o.namespace()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, err := client.ListPVCNames(namespace, "")
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		given := make(map[string]bool, len(args))
+		for _, arg := range args {
+			given[arg] = true
+		}
+		var completions []string
+		for _, name := range names {
+			if !given[name] {
+				completions = append(completions, name)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// registerOutputFlag defines -o with the given formats, the first one being the default
+func registerOutputFlag(cmd *cobra.Command, output *string, formats ...string) {
+	cmd.Flags().StringVarP(output, "output", "o", formats[0], "Output format: "+strings.Join(formats, ", "))
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(formats, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// validateOutput checks that output is one of the formats. Formats ending in
+// "=" take an argument, e.g. "jsonpath=" accepts "jsonpath={.items[*].pvc}".
+func validateOutput(output string, formats ...string) error {
+	for _, format := range formats {
+		if output == format || (strings.HasSuffix(format, "=") && strings.HasPrefix(output, format)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q (expected %s)", output, strings.Join(formats, ", "))
+}
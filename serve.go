@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+	"github.com/joseEnrique/pvcusage/internal/server"
+)
+
+// newServeCmd builds the serve command: an HTTP JSON API and web dashboard
+func newServeCmd(o *cliOptions) *cobra.Command {
+	var addr string
+	var interval time.Duration
+	var historySize int
+	var opts pvc.Options
+	var thresholds pvc.Thresholds
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve PVC usage over an HTTP JSON API and web dashboard",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 || historySize <= 0 {
+				return fmt.Errorf("--interval and --history must be positive")
+			}
+			if thresholds.Warn >= thresholds.Crit {
+				return fmt.Errorf("--crit (%v) must be greater than --warn (%v)", thresholds.Crit, thresholds.Warn)
+			}
+
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			if opts.Namespace, err = o.listNamespace(); err != nil {
+				return err
+			}
+
+			srv := server.New(client, opts, interval, historySize)
+			srv.SetThresholds(thresholds)
+			if err := srv.ListenAndServe(addr); err != nil {
+				return fmt.Errorf("error serving HTTP: %v", err)
+			}
+			return nil
+		},
+	}
+	// The API exposes usage read with the caller's credentials, so it is only
+	// reachable from other hosts when asked for, e.g. with --http :8080
+	cmd.Flags().StringVar(&addr, "http", "127.0.0.1:8080", "Address to serve the API and dashboard on")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "Interval between usage refreshes")
+	cmd.Flags().IntVar(&historySize, "history", 120, "Number of usage samples kept per PVC")
+	cmd.Flags().StringVar(&opts.NodeSelector, "node-selector", "", "Only collect usage from nodes matching this label selector (e.g. 'role=storage')")
+	cmd.Flags().Float64Var(&thresholds.Warn, "warn", 80, "Usage percentage at which a PVC is in the warning state")
+	cmd.Flags().Float64Var(&thresholds.Crit, "crit", 90, "Usage percentage at which a PVC is in the critical state")
+	return cmd
+}
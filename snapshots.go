@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/display"
+)
+
+// newSnapshotsCmd builds the snapshots command: VolumeSnapshots and the PVCs they were taken from
+func newSnapshotsCmd(o *cliOptions) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "snapshots",
+		Short: "List VolumeSnapshots with their source PVC, restore size and readiness",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "json", "yaml"); err != nil {
+				return err
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := o.listNamespace()
+			if err != nil {
+				return err
+			}
+
+			snapshots, err := client.GetSnapshots(namespace)
+			if err != nil {
+				return err
+			}
+			if output != "table" {
+				return display.PrintStructured(output, display.EmptyIfNil(snapshots))
+			}
+			display.ShowSnapshots(snapshots)
+			return nil
+		},
+	}
+	registerOutputFlag(cmd, &output, "table", "json", "yaml")
+	return cmd
+}
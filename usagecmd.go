@@ -0,0 +1,391 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joseEnrique/pvcusage/internal/alert"
+	"github.com/joseEnrique/pvcusage/internal/display"
+	"github.com/joseEnrique/pvcusage/internal/k8s"
+	"github.com/joseEnrique/pvcusage/internal/pvc"
+)
+
+// viewOptions holds the flags that shape the usage table
+type viewOptions struct {
+	filter     string
+	namespace  string // empty for all namespaces
+	workload   string
+	groupBy    string
+	sortBy     string
+	topN       int
+	thresholds pvc.Thresholds
+	colorMode  string
+	totals     bool                  // print a totals footer below the table
+	snapshots  bool                  // collect the VolumeSnapshots of each PVC
+	inline     bool                  // include emptyDir and CSI inline volumes
+	columns    []string              // optional table columns, nil for the defaults
+	color      bool                  // resolved from colorMode by validate
+	history    *pvc.History          // set in watch mode to show trends
+	printer    *display.UsagePrinter // set for custom-columns, go-template and jsonpath output
+}
+
+// usageOutputs are the -o formats of the usage command
+var usageOutputs = []string{"table", "json", "yaml", "custom-columns=", "go-template=", "go-template-file=", "jsonpath=", "jsonpath-file="}
+
+// usageReport is the JSON and YAML form of the usage table
+type usageReport struct {
+	Usages  []pvc.Usage `json:"usages"`
+	Groups  []pvc.Group `json:"groups,omitempty"`
+	Summary pvc.Totals  `json:"summary"`
+}
+
+// registerViewFlags defines the filtering flags shared by usage and watch
+func registerViewFlags(cmd *cobra.Command, view *viewOptions, opts *pvc.Options) {
+	cmd.Flags().StringVar(&view.filter, "filter", "", "Filter PVCs by usage percentage (e.g. '>50', '<=80', '=90')")
+	cmd.Flags().IntVar(&view.topN, "top", 0, "Show only the first N PVCs in sort order")
+	cmd.Flags().StringVar(&view.sortBy, "sort", "usage", "Sort PVCs by 'usage', 'used', 'size', 'name' or 'namespace'")
+	cmd.Flags().StringVar(&view.workload, "workload", "", "Filter PVCs by owning workload (e.g. 'StatefulSet/kafka', 'Deployment' or 'kafka')")
+	cmd.Flags().StringVar(&view.groupBy, "group-by", "", "Group PVCs by 'namespace' or 'workload'")
+	cmd.Flags().StringVar(&opts.NodeSelector, "node-selector", "", "Only collect usage from nodes matching this label selector (e.g. 'role=storage')")
+	cmd.Flags().Float64Var(&view.thresholds.Warn, "warn", 80, "Usage percentage at which a PVC is in the warning state")
+	cmd.Flags().Float64Var(&view.thresholds.Crit, "crit", 90, "Usage percentage at which a PVC is in the critical state")
+	cmd.Flags().StringVar(&view.colorMode, "color", "auto", "Color Use% by state: 'auto' (when writing to a terminal and NO_COLOR is unset), 'always' or 'never'")
+	cmd.Flags().BoolVar(&view.totals, "summary", false, "Print the totals of the listed PVCs below the table")
+	cmd.Flags().BoolVar(&view.snapshots, "snapshots", false, "Add the count, restore size and age of the VolumeSnapshots of each PVC")
+	cmd.Flags().BoolVar(&view.inline, "include-inline", false, "Also list emptyDir and CSI inline volumes as <pod>/<volume>, with a Type column")
+	cmd.Flags().StringSliceVar(&view.columns, "columns", nil, "Optional table columns to show: "+strings.Join(display.OptionalColumns, ", ")+" (default: workload and usage, plus type with --include-inline and snapshots with --snapshots)")
+	cmd.RegisterFlagCompletionFunc("columns", cobra.FixedCompletions(display.OptionalColumns, cobra.ShellCompDirectiveNoFileComp))
+	cmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions([]string{"auto", "always", "never"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions([]string{"namespace", "workload"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"usage", "used", "size", "name", "namespace"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// validate checks the view flags and resolves the color mode
+func (v *viewOptions) validate() error {
+	if err := pvc.SortUsages(nil, v.sortBy); err != nil {
+		return err
+	}
+	if _, _, err := pvc.ParseFilter(v.filter); err != nil {
+		return fmt.Errorf("invalid --filter: %v", err)
+	}
+	if v.columns == nil {
+		v.columns = []string{display.ColumnWorkload, display.ColumnUsage}
+		if v.inline {
+			v.columns = append(v.columns, display.ColumnType)
+		}
+		if v.snapshots {
+			v.columns = append(v.columns, display.ColumnSnapshots)
+		}
+	}
+	if _, err := display.SelectColumns(v.columns); err != nil {
+		return fmt.Errorf("invalid --columns: %v", err)
+	}
+	// The snapshot columns need the snapshots to be collected
+	for _, c := range v.columns {
+		if c == display.ColumnSnapshots {
+			v.snapshots = true
+		}
+	}
+	if v.thresholds.Warn >= v.thresholds.Crit {
+		return fmt.Errorf("--crit (%v) must be greater than --warn (%v)", v.thresholds.Crit, v.thresholds.Warn)
+	}
+	color, err := display.ColorEnabled(v.colorMode)
+	if err != nil {
+		return err
+	}
+	v.color = color
+	return nil
+}
+
+// newUsageCmd builds the usage command: a one-time usage table
+func newUsageCmd(o *cliOptions) *cobra.Command {
+	var view viewOptions
+	var opts pvc.Options
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show the usage of every mounted PVC (default command)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, usageOutputs...); err != nil {
+				return err
+			}
+			if err := view.validate(); err != nil {
+				return err
+			}
+			if display.IsTemplateOutput(output) {
+				if view.groupBy != "" {
+					return fmt.Errorf("--group-by is not supported with -o %s", strings.SplitN(output, "=", 2)[0])
+				}
+				printer, err := display.NewUsagePrinter(output)
+				if err != nil {
+					return err
+				}
+				view.printer = printer
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			if view.namespace, err = o.listNamespace(); err != nil {
+				return err
+			}
+
+			usages, err := collectUsages(client, opts, view)
+			if err != nil {
+				return err
+			}
+			return showUsages(usages, view, output)
+		},
+	}
+	registerViewFlags(cmd, &view, &opts)
+	registerOutputFlag(cmd, &output, usageOutputs...)
+	return cmd
+}
+
+// newWatchCmd builds the watch command: the usage table refreshed periodically,
+// or a stream of change events
+func newWatchCmd(o *cliOptions) *cobra.Command {
+	var view viewOptions
+	var opts pvc.Options
+	var output string
+	var interval time.Duration
+	var trendSamples int
+	var eventMinChange float64
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Refresh the usage table periodically, or stream changes as JSON lines",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output, "table", "events"); err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			if err := view.validate(); err != nil {
+				return err
+			}
+			client, err := o.client()
+			if err != nil {
+				return err
+			}
+			if view.namespace, err = o.listNamespace(); err != nil {
+				return err
+			}
+
+			var notifier *alert.Notifier
+			if o.profile != nil && len(o.profile.Alerts) > 0 {
+				if output != "events" {
+					log.Printf("Warning: the alert sinks of the profile are only used with -o events")
+				} else {
+					notifier = alert.NewNotifier(o.profile.Alerts)
+				}
+			}
+
+			if output == "events" {
+				runEventWatch(client, opts, view, interval, eventMinChange, notifier)
+				return nil
+			}
+			runTableWatch(client, opts, view, interval, trendSamples)
+			return nil
+		},
+	}
+	registerViewFlags(cmd, &view, &opts)
+	registerOutputFlag(cmd, &output, "table", "events")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Interval between refreshes")
+	cmd.Flags().IntVar(&trendSamples, "trend", 20, "Number of samples in the Trend column (0 disables it)")
+	cmd.Flags().Float64Var(&eventMinChange, "event-min-change", 1, "With -o events, minimum usage change in percentage points to emit an event")
+	return cmd
+}
+
+// runTableWatch redraws the usage table every interval until interrupted
+func runTableWatch(client *k8s.Client, opts pvc.Options, view viewOptions, interval time.Duration, trendSamples int) {
+	if trendSamples > 0 {
+		view.history = pvc.NewHistory(trendSamples)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	refresh := func() {
+		usages, err := collectUsages(client, opts, view)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if err := showUsages(usages, view, "table"); err != nil {
+			log.Printf("Error: %v", err)
+		}
+	}
+
+	// Show first update immediately
+	refresh()
+
+	// Then start the ticker for subsequent updates
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			display.ClearScreen()
+			refresh()
+		case <-sigs:
+			fmt.Println("\nTerminating watch mode...")
+			return
+		}
+	}
+}
+
+// runEventWatch refreshes PVC usage every interval and prints one JSON line per
+// change instead of the table. The first refresh reports every PVC as added.
+// Refreshes where a node can't be read are skipped, so that its PVCs aren't
+// reported as removed and added again. Events are also sent to the alert
+// sinks of notifier, if not nil.
+func runEventWatch(client *k8s.Client, opts pvc.Options, view viewOptions, interval time.Duration, minChange float64, notifier *alert.Notifier) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	// Events are computed on every PVC in scope and filtered afterwards, so that
+	// a PVC crossing the --filter boundary isn't reported as removed or added
+	filter := view.filter
+	view.filter = ""
+	opts.RequireAllNodes = true
+
+	encoder := json.NewEncoder(os.Stdout)
+	tracker := pvc.NewEventTracker(minChange, view.thresholds)
+	emit := func() {
+		usages, err := collectUsages(client, opts, view)
+		if err != nil {
+			log.Printf("Error: %v, skipping this refresh", err)
+			return
+		}
+		events, err := pvc.FilterEvents(tracker.Update(usages, time.Now()), filter)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		for _, event := range events {
+			if err := encoder.Encode(event); err != nil {
+				log.Printf("Error writing event: %v", err)
+			}
+		}
+		if notifier != nil {
+			for _, err := range notifier.Send(events) {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}
+
+	emit()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			emit()
+		case <-sigs:
+			return
+		}
+	}
+}
+
+// collectUsages gets the PVC usage data of the namespace and applies the
+// workload and usage filters, but not the top N limit
+func collectUsages(client *k8s.Client, opts pvc.Options, view viewOptions) ([]pvc.Usage, error) {
+	opts.Namespace = view.namespace
+	opts.IncludeInline = view.inline
+	usages, err := pvc.GetUsages(client, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting PVC usages: %v", err)
+	}
+
+	// Track every PVC, even those filtered out of this refresh
+	if view.history != nil {
+		view.history.Add(usages)
+	}
+
+	usages = pvc.FilterByWorkload(usages, view.workload)
+
+	// Then apply any additional filtering expression
+	filteredUsages, err := pvc.FilterUsages(usages, view.filter)
+	if err != nil {
+		return nil, fmt.Errorf("error filtering usages: %v", err)
+	}
+
+	if view.snapshots {
+		snapshots, err := client.GetSnapshots(view.namespace)
+		if err != nil {
+			return nil, err
+		}
+		pvc.AttachSnapshots(filteredUsages, k8s.SnapshotStatsByClaim(snapshots))
+	}
+	return filteredUsages, nil
+}
+
+// showUsages sorts usages, limits them to the top N, groups them if requested
+// and prints them as a table, JSON or YAML
+func showUsages(usages []pvc.Usage, view viewOptions, output string) error {
+	if err := pvc.SortUsages(usages, view.sortBy); err != nil {
+		return err
+	}
+
+	// Limit to top N if specified
+	limitedUsages := pvc.LimitTopN(usages, view.topN)
+
+	if view.printer != nil {
+		return view.printer.Print(limitedUsages)
+	}
+
+	var groups []pvc.Group
+	if view.groupBy != "" {
+		var err error
+		groups, err = pvc.GroupUsages(limitedUsages, view.groupBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if output != "table" {
+		return display.PrintStructured(output, usageReport{
+			Usages:  display.EmptyIfNil(limitedUsages),
+			Groups:  groups,
+			Summary: pvc.Summarize(limitedUsages, view.thresholds),
+		})
+	}
+
+	// Display results
+	table := display.NewTable()
+	table.SetThresholds(view.thresholds)
+	table.SetColor(view.color)
+	columns, err := display.SelectColumns(view.columns)
+	if err != nil {
+		return err
+	}
+	table.SetColumns(columns)
+	if view.history != nil {
+		table.SetHistory(view.history)
+	}
+	if groups != nil {
+		table.ShowGroups(groups)
+	} else {
+		table.Show(limitedUsages)
+	}
+	if view.totals {
+		table.ShowTotals(pvc.Summarize(limitedUsages, view.thresholds))
+	}
+	return nil
+}